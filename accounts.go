@@ -1,11 +1,81 @@
 package gotropipay
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // AddTropicardAccountRequest represents the payload to link a Tropicard
 type AddTropicardAccountRequest struct {
-	TropicardNumber string `json:"tropicardNumber"`
-	Pin             string `json:"pin"`
+	TropicardNumber string          `json:"tropicardNumber"`
+	Pin             SensitiveString `json:"pin"` // Wiped after the request is sent
+}
+
+func (r AddTropicardAccountRequest) wireBody() interface{} {
+	type plain AddTropicardAccountRequest
+	return struct {
+		plain
+		Pin wireSecret `json:"pin"`
+	}{plain(r), wireSecret(r.Pin)}
+}
+
+func (r AddTropicardAccountRequest) secrets() []SensitiveString {
+	return []SensitiveString{r.Pin}
+}
+
+// Account represents a Tropipay balance account (one per currency)
+type Account struct {
+	ID            int64       `json:"id"`
+	AccountNumber string      `json:"accountNumber"`
+	Alias         string      `json:"alias"`
+	Currency      string      `json:"currency"`
+	Type          int         `json:"type"`
+	State         int         `json:"state"`
+	IsDefault     bool        `json:"isDefault"`
+	Balance       int64       `json:"balance"`    // In cents, includes pending outgoing funds
+	PendingIn     int64       `json:"pendingIn"`  // Incoming funds not yet available
+	PendingOut    int64       `json:"pendingOut"` // Outgoing funds already committed
+	Tropicards    []Tropicard `json:"tropicards,omitempty"`
+	CreatedAt     string      `json:"createdAt"`
+	UpdatedAt     string      `json:"updatedAt"`
+}
+
+// Tropicard represents a Tropicard linked to an account
+type Tropicard struct {
+	ID           int64  `json:"id"`
+	MaskedNumber string `json:"maskedNumber"`
+	State        int    `json:"state"`
+	CreatedAt    string `json:"createdAt"`
+}
+
+// IDString returns the account ID in the form expected by path-based endpoints
+// such as ListAccountMovements and GetCryptoAddressForSelfCharge
+func (a Account) IDString() string {
+	return strconv.FormatInt(a.ID, 10)
+}
+
+// AccountBalance is the balance breakdown of a single account
+type AccountBalance struct {
+	AccountID  int64  `json:"accountId"`
+	Currency   string `json:"currency"`
+	Balance    int64  `json:"balance"`
+	Available  int64  `json:"available"` // Balance - PendingOut
+	PendingIn  int64  `json:"pendingIn"`
+	PendingOut int64  `json:"pendingOut"`
+}
+
+// BalanceBreakdown returns the balance breakdown of the account
+func (a Account) BalanceBreakdown() AccountBalance {
+	return AccountBalance{
+		AccountID:  a.ID,
+		Currency:   a.Currency,
+		Balance:    a.Balance,
+		Available:  a.Balance - a.PendingOut,
+		PendingIn:  a.PendingIn,
+		PendingOut: a.PendingOut,
+	}
 }
 
 // CryptoAddress represents a deposit address for a specific network and currency
@@ -22,16 +92,70 @@ type GetCryptoAddressResponse struct {
 	Accounts   []CryptoAddress `json:"accounts"`
 }
 
-// AddTropicardAccount links a Tropicard to the user's account.
-// It returns a generic map.
-// You can likely expect an "id" field in the response to use with other Account endpoints.
-func (c *Client) AddTropicardAccount(ctx context.Context, req AddTropicardAccountRequest) (map[string]interface{}, error) {
-	var resp map[string]interface{}
+// AddTropicardAccount links a Tropicard to the user's account and returns the resulting account.
+// The PIN is wiped once sent, so req cannot be reused; build a new one to retry.
+func (c *Client) AddTropicardAccount(ctx context.Context, req AddTropicardAccountRequest) (*Account, error) {
+	var resp Account
 	err := c.Request(ctx, "POST", "/accounts/", req, &resp)
 	if err != nil {
 		return nil, err
 	}
-	return resp, nil
+	return &resp, nil
+}
+
+// ListAccounts retrieves every balance account of the authenticated user.
+func (c *Client) ListAccounts(ctx context.Context) ([]Account, error) {
+	var accounts []Account
+	err := c.Request(ctx, "GET", "/accounts/", nil, &accounts)
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// GetAccount retrieves a single balance account.
+func (c *Client) GetAccount(ctx context.Context, accountID string) (*Account, error) {
+	var account Account
+	err := c.Request(ctx, "GET", "/accounts/"+accountID, nil, &account)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetAccountBalance retrieves the available and pending balances of an account.
+func (c *Client) GetAccountBalance(ctx context.Context, accountID string) (*AccountBalance, error) {
+	account, err := c.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	balance := account.BalanceBreakdown()
+	return &balance, nil
+}
+
+// GetAccountByCurrency returns the default account for a currency, or the
+// first one if none is marked as default.
+func (c *Client) GetAccountByCurrency(ctx context.Context, currency string) (*Account, error) {
+	accounts, err := c.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var found *Account
+	for i := range accounts {
+		if !strings.EqualFold(accounts[i].Currency, currency) {
+			continue
+		}
+		if accounts[i].IsDefault {
+			return &accounts[i], nil
+		}
+		if found == nil {
+			found = &accounts[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no account found for currency %s", currency)
+	}
+	return found, nil
 }
 
 // GetCryptoAddressForSelfCharge retrieves cryptocurrency addresses for depositing funds into a specific account.
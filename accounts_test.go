@@ -0,0 +1,86 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func accountsServer(t *testing.T, pin *string) *gotropipay.Client {
+	t.Helper()
+	accounts := []gotropipay.Account{
+		{ID: 1, Currency: "EUR", Balance: 10000, PendingIn: 500, PendingOut: 2500},
+		{ID: 2, Currency: "USD"},
+		{ID: 3, Currency: "USD", IsDefault: true},
+	}
+	return newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/accounts/":
+			writeJSON(w, accounts)
+		case r.Method == "GET" && r.URL.Path == "/accounts/1":
+			writeJSON(w, accounts[0])
+		case r.Method == "POST" && r.URL.Path == "/accounts/":
+			var body struct{ TropicardNumber, Pin string }
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			*pin = body.Pin
+			writeJSON(w, gotropipay.Account{ID: 4, Currency: "USD", Tropicards: []gotropipay.Tropicard{{ID: 9, MaskedNumber: "9238****" + body.TropicardNumber[len(body.TropicardNumber)-4:]}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestAccounts(t *testing.T) {
+	var pin string
+	client := accountsServer(t, &pin)
+	ctx := context.Background()
+
+	accounts, err := client.ListAccounts(ctx)
+	if err != nil || len(accounts) != 3 {
+		t.Fatalf("ListAccounts = %+v, %v", accounts, err)
+	}
+	acc, err := client.GetAccount(ctx, "1")
+	if err != nil || acc.ID != 1 || acc.Currency != "EUR" {
+		t.Fatalf("GetAccount = %+v, %v", acc, err)
+	}
+	if _, err := client.GetAccount(ctx, "99"); err == nil {
+		t.Error("expected error for a missing account")
+	}
+
+	bal, err := client.GetAccountBalance(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bal.Available != 7500 || bal.PendingIn != 500 || bal.AccountID != 1 || bal.Currency != "EUR" {
+		t.Errorf("balance = %+v", bal)
+	}
+
+	for _, currency := range []string{"USD", "usd"} {
+		if acc, err := client.GetAccountByCurrency(ctx, currency); err != nil || acc.ID != 3 {
+			t.Errorf("GetAccountByCurrency(%s) = %+v, %v; want the default account 3", currency, acc, err)
+		}
+	}
+	if _, err := client.GetAccountByCurrency(ctx, "GBP"); err == nil {
+		t.Error("expected error for a currency without account")
+	}
+}
+
+func TestAddTropicardAccount(t *testing.T) {
+	var pin string
+	client := accountsServer(t, &pin)
+
+	req := gotropipay.AddTropicardAccountRequest{TropicardNumber: "9238000011112222", Pin: gotropipay.NewSensitiveString("1234")}
+	acc, err := client.AddTropicardAccount(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pin != "1234" || acc.ID != 4 || len(acc.Tropicards) != 1 || acc.Tropicards[0].MaskedNumber != "9238****2222" {
+		t.Errorf("sent pin %q, account %+v", pin, acc)
+	}
+	if !req.Pin.IsZero() {
+		t.Error("PIN not wiped after sending")
+	}
+}
@@ -0,0 +1,230 @@
+// Package analytics computes revenue, volume and counterparty statistics over movements.
+package analytics
+
+import (
+	"iter"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// Interval is the width of a time bucket
+type Interval string
+
+const (
+	Daily   Interval = "day"
+	Weekly  Interval = "week" // Weeks start on Monday
+	Monthly Interval = "month"
+)
+
+// Options configures Compute
+type Options struct {
+	Interval Interval       // Default Daily
+	Location *time.Location // Bucket boundaries are computed in this zone (default UTC)
+	TopN     int            // Leaderboard size (default 10)
+
+	// RevenueStates lists the states counted in sums and leaderboards
+	// (default: completed). The state histogram always counts every movement.
+	RevenueStates []string
+}
+
+// Bucket aggregates movements in one interval and currency
+type Bucket struct {
+	Start    time.Time `json:"start"`
+	Currency string    `json:"currency"`
+	Count    int       `json:"count"`
+	Inflow   int64     `json:"inflow"`  // Sum of credits, in cents
+	Outflow  int64     `json:"outflow"` // Sum of debits, in cents (positive)
+	Fees     int64     `json:"fees"`    // Fees charged in this currency
+	Net      int64     `json:"net"`     // Inflow - Outflow
+}
+
+// CurrencyTotals aggregates all movements in one currency
+type CurrencyTotals struct {
+	Currency      string `json:"currency"`
+	Count         int    `json:"count"`
+	Inflow        int64  `json:"inflow"`
+	Outflow       int64  `json:"outflow"`
+	Fees          int64  `json:"fees"`
+	Net           int64  `json:"net"`
+	Credits       int    `json:"credits"`
+	AverageTicket int64  `json:"averageTicket"` // Inflow / Credits
+}
+
+// PartyTotal is a leaderboard entry
+type PartyTotal struct {
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	Count    int    `json:"count"`
+	Amount   int64  `json:"amount"`
+}
+
+// Report is the output of Compute
+type Report struct {
+	Interval      Interval         `json:"interval"`
+	Buckets       []Bucket         `json:"buckets"` // Ordered by start, then currency
+	Totals        []CurrencyTotals `json:"totals"`  // Ordered by currency
+	States        map[string]int   `json:"states"`
+	TopPayers     []PartyTotal     `json:"topPayers"`
+	TopRecipients []PartyTotal     `json:"topRecipients"`
+}
+
+// Compute consumes the movements once and builds the report
+func Compute(movements iter.Seq2[gotropipay.Movement, error], opts Options) (*Report, error) {
+	if opts.Interval == "" {
+		opts.Interval = Daily
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if opts.TopN <= 0 {
+		opts.TopN = 10
+	}
+	if len(opts.RevenueStates) == 0 {
+		opts.RevenueStates = []string{string(gotropipay.MovementStateCompleted)}
+	}
+
+	buckets := make(map[bucketKey]*Bucket)
+	totals := make(map[string]*CurrencyTotals)
+	payers := make(map[partyKey]*PartyTotal)
+	recipients := make(map[partyKey]*PartyTotal)
+	report := &Report{Interval: opts.Interval, States: make(map[string]int)}
+
+	for m, err := range movements {
+		if err != nil {
+			return nil, err
+		}
+		report.States[strings.ToLower(m.State)]++
+		if !counts(m.State, opts.RevenueStates) {
+			continue
+		}
+
+		amt := m.SignedAmount()
+		start := bucketStart(m.BookingTime().In(opts.Location), opts.Interval)
+		bucket := func(currency string) (*Bucket, *CurrencyTotals) {
+			bk := bucketKey{start, currency}
+			b := buckets[bk]
+			if b == nil {
+				b = &Bucket{Start: start, Currency: currency}
+				buckets[bk] = b
+			}
+			t := totals[currency]
+			if t == nil {
+				t = &CurrencyTotals{Currency: currency}
+				totals[currency] = t
+			}
+			return b, t
+		}
+
+		b, t := bucket(m.Currency)
+		b.Count++
+		t.Count++
+		// A conversion may charge the fee in another currency; book it there
+		fb, ft := b, t
+		if m.Fee.Currency != "" && !strings.EqualFold(m.Fee.Currency, m.Currency) {
+			fb, ft = bucket(m.Fee.Currency)
+		}
+		fb.Fees += m.Fee.Amount
+		ft.Fees += m.Fee.Amount
+		if amt >= 0 {
+			b.Inflow += amt
+			t.Inflow += amt
+			t.Credits++
+			addParty(payers, partyKey{m.SenderName(), m.Currency}, amt)
+		} else {
+			b.Outflow -= amt
+			t.Outflow -= amt
+			addParty(recipients, partyKey{m.RecipientName(), m.Currency}, -amt)
+		}
+	}
+
+	for _, b := range buckets {
+		b.Net = b.Inflow - b.Outflow
+		report.Buckets = append(report.Buckets, *b)
+	}
+	sort.Slice(report.Buckets, func(i, j int) bool {
+		a, b := report.Buckets[i], report.Buckets[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		return a.Currency < b.Currency
+	})
+
+	for _, t := range totals {
+		t.Net = t.Inflow - t.Outflow
+		if t.Credits > 0 {
+			t.AverageTicket = t.Inflow / int64(t.Credits)
+		}
+		report.Totals = append(report.Totals, *t)
+	}
+	sort.Slice(report.Totals, func(i, j int) bool { return report.Totals[i].Currency < report.Totals[j].Currency })
+
+	report.TopPayers = leaderboard(payers, opts.TopN)
+	report.TopRecipients = leaderboard(recipients, opts.TopN)
+	return report, nil
+}
+
+type bucketKey struct {
+	start    time.Time
+	currency string
+}
+
+type partyKey struct {
+	name     string
+	currency string
+}
+
+func addParty(m map[partyKey]*PartyTotal, key partyKey, amount int64) {
+	// Anonymous movements are not ranked
+	if key.name == "" {
+		return
+	}
+	p := m[key]
+	if p == nil {
+		p = &PartyTotal{Name: key.name, Currency: key.currency}
+		m[key] = p
+	}
+	p.Count++
+	p.Amount += amount
+}
+
+func leaderboard(m map[partyKey]*PartyTotal, n int) []PartyTotal {
+	out := make([]PartyTotal, 0, len(m))
+	for _, p := range m {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Amount != out[j].Amount {
+			return out[i].Amount > out[j].Amount
+		}
+		return out[i].Name < out[j].Name
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+func counts(state string, states []string) bool {
+	for _, s := range states {
+		if strings.EqualFold(state, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func bucketStart(t time.Time, interval Interval) time.Time {
+	y, mo, d := t.Date()
+	switch interval {
+	case Monthly:
+		return time.Date(y, mo, 1, 0, 0, 0, 0, t.Location())
+	case Weekly:
+		offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+		return time.Date(y, mo, d-offset, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+	}
+}
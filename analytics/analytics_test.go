@@ -0,0 +1,110 @@
+package analytics_test
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+	"github.com/tropipay/gotropipay/analytics"
+)
+
+func seq(ms ...gotropipay.Movement) iter.Seq2[gotropipay.Movement, error] {
+	return func(yield func(gotropipay.Movement, error) bool) {
+		for _, m := range ms {
+			if !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestComputeWeekly(t *testing.T) {
+	ana := &gotropipay.MovementParty{Name: "Ana"}
+	bob := &gotropipay.MovementParty{Name: "Bob"}
+	r, err := analytics.Compute(seq(
+		// Monday and Sunday of the same week
+		gotropipay.Movement{Amount: 1000, Fee: gotropipay.NewMoney(35, "EUR"), Currency: "EUR", State: "completed", CreatedAt: "2026-10-12T10:00:00Z", Sender: ana},
+		gotropipay.Movement{Amount: 3000, Fee: gotropipay.NewMoney(105, "EUR"), Currency: "EUR", State: "completed", CreatedAt: "2026-10-18T23:00:00Z", Sender: bob},
+		gotropipay.Movement{Amount: 500, Currency: "EUR", State: "completed", CreatedAt: "2026-10-19T08:00:00Z", Sender: ana},
+		gotropipay.Movement{Amount: 700, Currency: "EUR", State: "completed", CreatedAt: "2026-10-19T09:00:00Z", BalanceBefore: 5000, BalanceAfter: 4300},
+		gotropipay.Movement{Amount: 9999, Currency: "EUR", State: "failed", CreatedAt: "2026-10-19T09:00:00Z", Sender: bob},
+	), analytics.Options{Interval: analytics.Weekly})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Buckets) != 2 || r.Buckets[0].Inflow != 4000 || r.Buckets[0].Fees != 140 || r.Buckets[1].Net != -200 {
+		t.Errorf("buckets: %+v", r.Buckets)
+	}
+	if tot := r.Totals[0]; tot.AverageTicket != 1500 || tot.Outflow != 700 {
+		t.Errorf("totals: %+v", tot)
+	}
+	if r.States["completed"] != 4 || r.States["failed"] != 1 {
+		t.Errorf("states: %v", r.States)
+	}
+	if len(r.TopPayers) != 2 || r.TopPayers[0].Name != "Bob" || r.TopPayers[1].Amount != 1500 {
+		t.Errorf("payers: %+v", r.TopPayers)
+	}
+	if !r.Buckets[0].Start.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("week should start on Monday, got %s", r.Buckets[0].Start)
+	}
+}
+
+func TestComputeBooksFeesInTheirCurrency(t *testing.T) {
+	r, err := analytics.Compute(seq(
+		// A USD payment converted into EUR, with the fee charged in EUR
+		gotropipay.Movement{Amount: 1000, Fee: gotropipay.NewMoney(40, "EUR"), Currency: "USD", State: "completed", CreatedAt: "2026-10-12T10:00:00Z"},
+		gotropipay.Movement{Amount: 2000, Fee: gotropipay.NewMoney(60, "EUR"), Currency: "EUR", State: "completed", CreatedAt: "2026-10-12T11:00:00Z"},
+	), analytics.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fees := map[string]int64{}
+	for _, tot := range r.Totals {
+		fees[tot.Currency] = tot.Fees
+	}
+	if fees["EUR"] != 100 || fees["USD"] != 0 {
+		t.Errorf("fees = %v, want 1.00 EUR and no USD", fees)
+	}
+}
+
+type fakeGraphQL struct {
+	query string
+	vars  map[string]interface{}
+}
+
+func (f *fakeGraphQL) GraphQL(ctx context.Context, query string, vars map[string]interface{}, out interface{}) error {
+	f.query, f.vars = query, vars
+	return json.Unmarshal([]byte(`{"s0": {"totalCount": 4}, "s1": {"totalCount": 17}}`), out)
+}
+
+func TestStateCounts(t *testing.T) {
+	gql := &fakeGraphQL{}
+	counts, err := analytics.StateCounts(context.Background(), gql, &gotropipay.MovementFilter{Currency: "EUR", State: []string{"ignored"}}, "pending", "completed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 2 || counts["pending"] != 4 || counts["completed"] != 17 {
+		t.Errorf("counts = %v", counts)
+	}
+	if !strings.Contains(gql.query, "s0: movements(filter: $f0, pagination: $pagination) { totalCount }") ||
+		!strings.Contains(gql.query, "$f1: MovementFilter") {
+		t.Errorf("query = %s", gql.query)
+	}
+	f1, ok := gql.vars["f1"].(gotropipay.MovementFilter)
+	if !ok || f1.Currency != "EUR" || len(f1.State) != 1 || f1.State[0] != "completed" {
+		t.Errorf("f1 = %+v", gql.vars["f1"])
+	}
+	if p, _ := gql.vars["pagination"].(map[string]int); p["limit"] != 0 {
+		t.Errorf("pagination = %v, want no rows", gql.vars["pagination"])
+	}
+
+	// Every state is asked for by default
+	if counts, err := analytics.StateCounts(context.Background(), gql, nil); err != nil || len(counts) != 4 {
+		t.Errorf("default counts = %v, %v", counts, err)
+	}
+}
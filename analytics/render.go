@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteJSON renders the full report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteBucketsCSV renders the time series, one row per bucket and currency
+func (r *Report) WriteBucketsCSV(w io.Writer) error {
+	rows := [][]string{{"start", "currency", "count", "inflow", "outflow", "fees", "net"}}
+	for _, b := range r.Buckets {
+		rows = append(rows, []string{
+			b.Start.Format(time.DateOnly), b.Currency, strconv.Itoa(b.Count),
+			itoa(b.Inflow), itoa(b.Outflow), itoa(b.Fees), itoa(b.Net),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteTotalsCSV renders per-currency totals including average ticket
+func (r *Report) WriteTotalsCSV(w io.Writer) error {
+	rows := [][]string{{"currency", "count", "inflow", "outflow", "fees", "net", "average_ticket"}}
+	for _, t := range r.Totals {
+		rows = append(rows, []string{
+			t.Currency, strconv.Itoa(t.Count), itoa(t.Inflow), itoa(t.Outflow),
+			itoa(t.Fees), itoa(t.Net), itoa(t.AverageTicket),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteLeaderboardCSV renders payers and recipients with a "role" column
+func (r *Report) WriteLeaderboardCSV(w io.Writer) error {
+	rows := [][]string{{"role", "rank", "name", "currency", "count", "amount"}}
+	for i, p := range r.TopPayers {
+		rows = append(rows, []string{"payer", strconv.Itoa(i + 1), p.Name, p.Currency, strconv.Itoa(p.Count), itoa(p.Amount)})
+	}
+	for i, p := range r.TopRecipients {
+		rows = append(rows, []string{"recipient", strconv.Itoa(i + 1), p.Name, p.Currency, strconv.Itoa(p.Count), itoa(p.Amount)})
+	}
+	return writeCSV(w, rows)
+}
+
+func writeCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,62 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// GraphQLClient runs GraphQL documents. *gotropipay.Client implements it.
+type GraphQLClient interface {
+	GraphQL(ctx context.Context, query string, vars map[string]interface{}, out interface{}) error
+}
+
+// StateCounts asks the business endpoint for the number of movements in each
+// state using its totalCount aggregate, in a single request and without
+// downloading any rows. The filter's own State list is ignored.
+func StateCounts(ctx context.Context, c GraphQLClient, filter *gotropipay.MovementFilter, states ...string) (map[string]int, error) {
+	if len(states) == 0 {
+		states = []string{
+			string(gotropipay.MovementStatePending),
+			string(gotropipay.MovementStateCompleted),
+			string(gotropipay.MovementStateFailed),
+			string(gotropipay.MovementStateCancelled),
+		}
+	}
+
+	var params []string
+	var fields []string
+	vars := map[string]interface{}{
+		"pagination": map[string]int{"limit": 0, "offset": 0},
+	}
+	for i, state := range states {
+		f := gotropipay.MovementFilter{}
+		if filter != nil {
+			f = *filter
+		}
+		f.State = []string{state}
+
+		name := fmt.Sprintf("f%d", i)
+		vars[name] = f
+		params = append(params, fmt.Sprintf("$%s: MovementFilter", name))
+		fields = append(fields, fmt.Sprintf("s%d: movements(filter: $%s, pagination: $pagination) { totalCount }", i, name))
+	}
+
+	query := "query MovementStateCounts(" + strings.Join(params, ", ") + ", $pagination: PaginationInput) { " +
+		strings.Join(fields, " ") + " }"
+
+	var out map[string]struct {
+		TotalCount int `json:"totalCount"`
+	}
+	if err := c.GraphQL(ctx, query, vars, &out); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(states))
+	for i, state := range states {
+		counts[state] = out[fmt.Sprintf("s%d", i)].TotalCount
+	}
+	return counts, nil
+}
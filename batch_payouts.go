@@ -0,0 +1,526 @@
+package gotropipay
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchItem is one transfer of a batch payout
+type BatchItem struct {
+	Beneficiary DepositAccount // Only ID is required
+	Amount      Money
+	Reference   string // Must be unique within the batch
+	Concept     string
+}
+
+// BatchItemStatus is the outcome of a batch item
+type BatchItemStatus string
+
+const (
+	BatchItemPending   BatchItemStatus = "pending"   // Validated and simulated, not executed (dry run)
+	BatchItemInvalid   BatchItemStatus = "invalid"   // Failed validation or simulation
+	BatchItemCompleted BatchItemStatus = "completed" // Payout booked
+	BatchItemFailed    BatchItemStatus = "failed"    // Booking was rejected
+	BatchItemInDoubt   BatchItemStatus = "in_doubt"  // Interrupted mid-booking and not safe to retry; check manually
+)
+
+// BatchOptions configures BatchPayout
+type BatchOptions struct {
+	// JournalPath is the append-only progress file. Re-running a batch with
+	// the same journal resumes it without re-booking completed items.
+	JournalPath string
+	// Concurrency bounds parallel API calls (default 4)
+	Concurrency int
+	// AccountID is the source account; 0 uses the default for each currency
+	AccountID int64
+	// ReasonID is the payment reason sent with every payout
+	ReasonID int
+	// SecurityCode returns the code for each booking. When nil, the client's
+	// SecurityCodeProvider is asked instead.
+	SecurityCode func(ctx context.Context, item BatchItem) (string, error)
+	// SkipValidation skips the ValidateAccountNumber round-trip
+	SkipValidation bool
+	// DryRun validates and simulates every item without booking anything
+	DryRun bool
+	// Confirm, if set, is shown the simulated totals before anything is
+	// booked. Returning an error aborts the batch. Not called on dry runs.
+	Confirm func(*BatchReport) error
+	// OnProgress, if set, is called as each item settles
+	OnProgress func(BatchItemResult)
+}
+
+// BatchItemResult is the outcome of a single item
+type BatchItemResult struct {
+	Index          int               `json:"index"`
+	Item           BatchItem         `json:"-"`
+	IdempotencyKey string            `json:"idempotencyKey"`
+	Status         BatchItemStatus   `json:"status"`
+	Simulation     *PayoutSimulation `json:"simulation,omitempty"`
+	Payout         *Payout           `json:"payout,omitempty"`
+	Error          string            `json:"error,omitempty"`
+}
+
+// BatchReport summarises a batch run
+type BatchReport struct {
+	Items     []BatchItemResult
+	Totals    map[string]Money // Simulated amount + fees per source currency, for items not yet booked
+	Completed int
+	Failed    int
+	Invalid   int
+	InDoubt   int
+}
+
+func newBatchReport(results []BatchItemResult) *BatchReport {
+	report := &BatchReport{Items: results, Totals: make(map[string]Money)}
+	for _, r := range results {
+		switch r.Status {
+		case BatchItemCompleted:
+			report.Completed++
+		case BatchItemFailed:
+			report.Failed++
+		case BatchItemInvalid:
+			report.Invalid++
+		case BatchItemInDoubt:
+			report.InDoubt++
+		}
+		if r.Simulation != nil {
+			cur := r.Simulation.Total.Currency
+			report.Totals[cur], _ = report.Totals[cur].Add(r.Simulation.Total)
+		}
+	}
+	return report
+}
+
+// ParseBatchCSV reads items from a CSV with a header row containing
+// beneficiary_id, amount, currency, reference and optionally concept.
+// Amounts are decimals ("150.25").
+func ParseBatchCSV(r io.Reader) ([]BatchItem, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	col := make(map[string]int)
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"beneficiary_id", "amount", "currency", "reference"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("missing CSV column %q", required)
+		}
+	}
+
+	var items []BatchItem
+	seen := make(map[string]int)
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := strconv.Atoi(rec[col["beneficiary_id"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid beneficiary_id: %w", line, err)
+		}
+		amount, err := ParseMoney(rec[col["amount"]], rec[col["currency"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		item := BatchItem{
+			Beneficiary: DepositAccount{ID: id},
+			Amount:      amount,
+			Reference:   rec[col["reference"]],
+		}
+		if prev, ok := seen[item.Reference]; ok && item.Reference != "" {
+			return nil, fmt.Errorf("line %d: reference %q already used on line %d", line, item.Reference, prev)
+		}
+		seen[item.Reference] = line
+		if i, ok := col["concept"]; ok && i < len(rec) {
+			item.Concept = rec[i]
+		}
+		items = append(items, item)
+	}
+}
+
+// BatchPayout validates every beneficiary, simulates the totals and then books
+// the payouts with bounded concurrency. Progress is journaled before and after
+// each booking, and every item carries a deterministic idempotency key, so a
+// crashed run can be resumed without paying anyone twice.
+func (c *Client) BatchPayout(ctx context.Context, items []BatchItem, opts BatchOptions) (*BatchReport, error) {
+	if opts.JournalPath == "" {
+		return nil, errors.New("batch payout requires a journal path")
+	}
+	if !opts.DryRun && opts.SecurityCode == nil && c.securityCodes == nil {
+		return nil, fmt.Errorf("batch payout: %w", ErrSecurityCodeRequired)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	seen := make(map[string]int)
+	for i, item := range items {
+		if prev, ok := seen[item.Reference]; ok && item.Reference != "" {
+			return nil, fmt.Errorf("batch item %d: reference %q already used by item %d", i, item.Reference, prev)
+		}
+		seen[item.Reference] = i
+	}
+
+	batchID := batchFingerprint(items)
+	journal, err := openBatchJournal(opts.JournalPath, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer journal.Close()
+
+	results := make([]BatchItemResult, len(items))
+	for i, item := range items {
+		results[i] = BatchItemResult{
+			Index:          i,
+			Item:           item,
+			IdempotencyKey: batchItemKey(batchID, i, item),
+			Status:         BatchItemPending,
+		}
+	}
+
+	// Phase 1: validate and simulate everything not already settled, so the
+	// totals only cover what is still to be booked
+	forEachBounded(ctx, len(items), opts.Concurrency, func(i int) {
+		if s := journal.state(i).Status; s == batchJournalDone || s == batchJournalFailed {
+			return
+		}
+		sim, err := c.prepareBatchItem(ctx, items[i], opts)
+		if err != nil {
+			results[i].Status = BatchItemInvalid
+			results[i].Error = err.Error()
+			return
+		}
+		results[i].Simulation = sim
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if !opts.DryRun && opts.Confirm != nil {
+		if err := opts.Confirm(newBatchReport(results)); err != nil {
+			return newBatchReport(results), fmt.Errorf("batch payout not confirmed: %w", err)
+		}
+	}
+
+	// Phase 2: book
+	if !opts.DryRun {
+		forEachBounded(ctx, len(items), opts.Concurrency, func(i int) {
+			c.executeBatchItem(ctx, &results[i], journal, opts)
+			if opts.OnProgress != nil {
+				opts.OnProgress(results[i])
+			}
+		})
+	}
+
+	return newBatchReport(results), ctx.Err()
+}
+
+func (c *Client) prepareBatchItem(ctx context.Context, item BatchItem, opts BatchOptions) (*PayoutSimulation, error) {
+	if !opts.SkipValidation {
+		beneficiary, err := c.GetDepositAccount(ctx, item.Beneficiary.ID)
+		if err != nil {
+			return nil, err
+		}
+		val, err := c.ValidateAccountNumber(ctx, ValidateAccountNumberRequest{
+			AccountNumber:        beneficiary.AccountNumber,
+			CountryDestinationID: beneficiary.CountryDestinationID,
+			Type:                 beneficiary.Type,
+			Currency:             item.Amount.Currency,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !val.Valid {
+			return nil, fmt.Errorf("beneficiary %d failed validation: %v", item.Beneficiary.ID, val.ErrorMessage)
+		}
+	}
+	return c.SimulatePayout(ctx, batchPayoutRequest(item, opts))
+}
+
+func (c *Client) executeBatchItem(ctx context.Context, r *BatchItemResult, journal *batchJournal, opts BatchOptions) {
+	prev := journal.state(r.Index)
+	switch prev.Status {
+	case batchJournalDone:
+		r.Status = BatchItemCompleted
+		r.Payout = prev.Payout
+		return
+	case batchJournalFailed:
+		r.Status = BatchItemFailed
+		r.Error = prev.Error
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	// A "started" entry without an outcome means we crashed mid-call. If the
+	// reference shows up as a movement created since the journal was opened,
+	// the transfer went through; older movements belong to earlier batches
+	// reusing the reference. This runs before the validation result is
+	// considered: an item that was booked must be reported as such even if
+	// it no longer validates.
+	if prev.Status == batchJournalStarted {
+		var m *Movement
+		var err error
+		if r.Item.Reference != "" && !journal.created.IsZero() {
+			m, err = c.findPaymentMovement(ctx, r.Item.Reference, journal.created)
+		}
+		switch {
+		case err != nil:
+			r.Status = BatchItemInDoubt
+			r.Error = fmt.Sprintf("interrupted booking could not be checked: %v", err)
+			return
+		case m != nil && !failedState(m.State):
+			r.Status = BatchItemCompleted
+			r.Payout = &Payout{ID: m.IDString(), Reference: m.Reference, State: PayoutState(strings.ToLower(m.State))}
+			r.Error = ""
+			journal.record(batchJournalEntry{Index: r.Index, Key: r.IdempotencyKey, Status: batchJournalDone, Payout: r.Payout})
+			return
+		case r.Status == BatchItemInvalid || r.Item.Reference == "" || journal.created.IsZero():
+			// Not rebooked, so the idempotency key cannot settle whether it went through
+			r.Status = BatchItemInDoubt
+			r.Error = "interrupted booking not found: " + r.Error
+			return
+		}
+		// Not found: rebooking with the same idempotency key is safe
+	}
+	if r.Status == BatchItemInvalid {
+		return
+	}
+
+	if err := journal.record(batchJournalEntry{Index: r.Index, Key: r.IdempotencyKey, Status: batchJournalStarted}); err != nil {
+		r.Status = BatchItemFailed
+		r.Error = err.Error()
+		return
+	}
+
+	req := batchPayoutRequest(r.Item, opts)
+	req.IdempotencyKey = r.IdempotencyKey
+	var err error
+	if opts.SecurityCode != nil {
+		req.SecurityCode, err = opts.SecurityCode(ctx, r.Item)
+	}
+	if err == nil {
+		r.Payout, err = c.CreatePayout(ctx, req)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			// Leave the item in doubt; a resumed run will check for it
+			r.Error = err.Error()
+			return
+		}
+		r.Status = BatchItemFailed
+		r.Error = err.Error()
+		journal.record(batchJournalEntry{Index: r.Index, Key: r.IdempotencyKey, Status: batchJournalFailed, Error: r.Error})
+		return
+	}
+
+	r.Status = BatchItemCompleted
+	r.Error = ""
+	journal.record(batchJournalEntry{Index: r.Index, Key: r.IdempotencyKey, Status: batchJournalDone, Payout: r.Payout})
+}
+
+func batchPayoutRequest(item BatchItem, opts BatchOptions) PayoutRequest {
+	return PayoutRequest{
+		Beneficiary: item.Beneficiary,
+		Amount:      item.Amount,
+		AccountID:   opts.AccountID,
+		Concept:     item.Concept,
+		ReasonID:    opts.ReasonID,
+		Reference:   item.Reference,
+	}
+}
+
+func failedState(state string) bool {
+	s := MovementState(strings.ToLower(state))
+	return s == MovementStateFailed || s == MovementStateCancelled
+}
+
+// WriteCSV renders one row per item
+func (r *BatchReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"index", "beneficiary_id", "amount", "currency", "reference", "status", "payout_id", "fee", "error"}); err != nil {
+		return err
+	}
+	for _, it := range r.Items {
+		payoutID, fee := "", ""
+		if it.Payout != nil {
+			payoutID = it.Payout.ID
+		}
+		if it.Simulation != nil {
+			fee = it.Simulation.Fee.Decimal()
+		}
+		row := []string{
+			strconv.Itoa(it.Index), strconv.Itoa(it.Item.Beneficiary.ID), it.Item.Amount.Decimal(), it.Item.Amount.Currency,
+			it.Item.Reference, string(it.Status), payoutID, fee, it.Error,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// forEachBounded calls fn for 0..n-1 with at most limit calls in flight
+func forEachBounded(ctx context.Context, n, limit int, fn func(int)) {
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func batchFingerprint(items []BatchItem) string {
+	h := sha256.New()
+	for _, it := range items {
+		fmt.Fprintf(h, "%d|%d|%s|%s\n", it.Beneficiary.ID, it.Amount.Amount, it.Amount.Currency, it.Reference)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func batchItemKey(batchID string, index int, item BatchItem) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s", batchID, index, item.Reference)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Journal
+
+const (
+	batchJournalStarted = "started"
+	batchJournalDone    = "done"
+	batchJournalFailed  = "failed"
+)
+
+type batchJournalEntry struct {
+	Batch   string    `json:"batch,omitempty"`  // Only set on the header line
+	Created time.Time `json:"created,omitzero"` // Only set on the header line
+	Index   int       `json:"index"`
+	Key     string    `json:"key,omitempty"`
+	Status  string    `json:"status,omitempty"`
+	Payout  *Payout   `json:"payout,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// batchJournal is an append-only JSON-lines log synced to disk after every entry
+type batchJournal struct {
+	mu      sync.Mutex
+	file    *os.File
+	created time.Time // When the batch was first started; zero for older journals
+	states  map[int]batchJournalEntry
+}
+
+func openBatchJournal(path, batchID string) (*batchJournal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	j := &batchJournal{file: f, states: make(map[int]batchJournalEntry)}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	// A crash mid-write leaves a torn last line. Cut it off so the next
+	// entry starts on a fresh line instead of being merged into it.
+	complete := bytes.LastIndexByte(data, '\n') + 1
+	if complete < len(data) {
+		if err := f.Truncate(int64(complete)); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to repair journal: %w", err)
+		}
+		data = data[:complete]
+	}
+
+	first := true
+	lines := bytes.Split(data, []byte("\n"))
+	for n, line := range lines[:len(lines)-1] { // data ends in '\n', so the last element is empty
+		var e batchJournalEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("journal %s is corrupt at line %d: %w", path, n+1, err)
+		}
+		if first {
+			first = false
+			if e.Batch != batchID {
+				f.Close()
+				return nil, fmt.Errorf("journal %s belongs to a different batch", path)
+			}
+			j.created = e.Created
+			continue
+		}
+		j.states[e.Index] = e
+	}
+
+	if first {
+		j.created = time.Now().UTC().Truncate(time.Second)
+		if err := j.record(batchJournalEntry{Batch: batchID, Created: j.created, Index: -1}); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+func (j *batchJournal) state(i int) batchJournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.states[i]
+}
+
+func (j *batchJournal) record(e batchJournalEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := j.file.Sync(); err != nil {
+		return err
+	}
+	if e.Index >= 0 {
+		j.states[e.Index] = e
+	}
+	return nil
+}
+
+func (j *batchJournal) Close() error {
+	return j.file.Close()
+}
@@ -0,0 +1,333 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestBatchPayoutResumesWithoutDoublePaying(t *testing.T) {
+	var booked atomic.Int32
+	keys := make(chan string, 10)
+
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/booking/payout/simulate":
+			writeJSON(w, map[string]interface{}{"amount": 1000, "currency": "EUR", "fee": 50})
+		case "/booking/payout":
+			n := booked.Add(1)
+			keys <- r.Header.Get("Idempotency-Key")
+			writeJSON(w, map[string]interface{}{"id": n, "state": "pending", "amount": 1000, "currency": "EUR"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	items, err := gotropipay.ParseBatchCSV(strings.NewReader(
+		"beneficiary_id,amount,currency,reference\n1,10.00,EUR,PAY-1\n2,10.00,EUR,PAY-2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := gotropipay.BatchOptions{
+		JournalPath:    filepath.Join(t.TempDir(), "batch.journal"),
+		SkipValidation: true,
+		SecurityCode: func(context.Context, gotropipay.BatchItem) (string, error) {
+			return "123456", nil
+		},
+	}
+
+	report, err := client.BatchPayout(context.Background(), items, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Completed != 2 || booked.Load() != 2 {
+		t.Fatalf("completed %d, booked %d; want 2, 2", report.Completed, booked.Load())
+	}
+	if total := report.Totals["EUR"]; total.Amount != 2100 {
+		t.Errorf("total = %v, want 21.00 EUR", total)
+	}
+	if k1, k2 := <-keys, <-keys; k1 == "" || k1 == k2 {
+		t.Errorf("idempotency keys %q and %q should be set and distinct", k1, k2)
+	}
+
+	// Re-running with the same journal must not book anything again
+	report, err = client.BatchPayout(context.Background(), items, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Completed != 2 || booked.Load() != 2 {
+		t.Fatalf("resume: completed %d, booked %d; want 2, 2", report.Completed, booked.Load())
+	}
+
+	// A different batch cannot reuse the journal
+	if _, err := client.BatchPayout(context.Background(), items[:1], opts); err == nil {
+		t.Error("expected error reusing journal for a different batch")
+	}
+}
+
+// crashedJournal writes the journal of a batch that was interrupted while
+// booking item 0 and then torn mid-write
+func crashedJournal(t *testing.T, client *gotropipay.Client, items []gotropipay.BatchItem) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "batch.journal")
+	// A dry run only writes the header
+	if _, err := client.BatchPayout(context.Background(), items, gotropipay.BatchOptions{JournalPath: path, SkipValidation: true, DryRun: true}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString(`{"index":0,"status":"started"}` + "\n" + `{"index":1,"sta`)
+	return path
+}
+
+func TestBatchPayoutResumesInterruptedBooking(t *testing.T) {
+	booked := map[string]int{}
+	var mu sync.Mutex
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/booking/payout/simulate":
+			writeJSON(w, map[string]interface{}{"amount": 1000, "currency": "EUR"})
+		case "/booking/payout":
+			var body struct{ Reference string }
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			booked[body.Reference]++
+			mu.Unlock()
+			writeJSON(w, map[string]interface{}{"id": 5, "state": "pending", "amount": 1000, "currency": "EUR"})
+		case "/movements/":
+			// PAY-1 went through before the crash
+			if strings.Contains(r.URL.Query().Get("query"), "PAY-1") {
+				writeJSON(w, map[string]interface{}{"items": []map[string]interface{}{
+					{"id": 77, "reference": "PAY-1", "state": "pending", "createdAt": time.Now().UTC().Format(time.RFC3339)},
+				}})
+				return
+			}
+			writeJSON(w, map[string]interface{}{"items": []interface{}{}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	items := []gotropipay.BatchItem{
+		{Beneficiary: gotropipay.DepositAccount{ID: 1}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "PAY-1"},
+		{Beneficiary: gotropipay.DepositAccount{ID: 2}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "PAY-2"},
+	}
+	path := crashedJournal(t, client, items)
+
+	report, err := client.BatchPayout(context.Background(), items, gotropipay.BatchOptions{
+		JournalPath: path, SkipValidation: true,
+		SecurityCode: func(context.Context, gotropipay.BatchItem) (string, error) { return "1", nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if booked["PAY-1"] != 0 || booked["PAY-2"] != 1 {
+		t.Errorf("booked = %v, want PAY-1 looked up and only PAY-2 booked", booked)
+	}
+	if it := report.Items[0]; it.Status != gotropipay.BatchItemCompleted || it.Payout == nil || it.Payout.ID != "77" {
+		t.Errorf("item 0 = %+v", it)
+	}
+
+	// The torn line was cut off, so every line of the journal still parses
+	data, _ := os.ReadFile(path)
+	for i, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("journal line %d is not valid JSON: %s", i+1, line)
+		}
+	}
+}
+
+func TestBatchPayoutIgnoresMovementsFromEarlierBatches(t *testing.T) {
+	var booked atomic.Int32
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/booking/payout/simulate":
+			writeJSON(w, map[string]interface{}{"amount": 1000, "currency": "EUR"})
+		case "/booking/payout":
+			booked.Add(1)
+			writeJSON(w, map[string]interface{}{"id": 9, "state": "pending"})
+		case "/movements/":
+			// Last month's run used the same reference
+			writeJSON(w, map[string]interface{}{"items": []map[string]interface{}{
+				{"id": 12, "reference": "SALARY-1", "state": "completed", "createdAt": "2020-01-31T10:00:00Z"},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	items := []gotropipay.BatchItem{
+		{Beneficiary: gotropipay.DepositAccount{ID: 1}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "SALARY-1"},
+		{Beneficiary: gotropipay.DepositAccount{ID: 2}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "SALARY-2"},
+	}
+	path := crashedJournal(t, client, items)
+
+	report, err := client.BatchPayout(context.Background(), items, gotropipay.BatchOptions{
+		JournalPath: path, SkipValidation: true,
+		SecurityCode: func(context.Context, gotropipay.BatchItem) (string, error) { return "1", nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it := report.Items[0]; it.Status != gotropipay.BatchItemCompleted || it.Payout == nil || it.Payout.ID != "9" {
+		t.Errorf("item 0 = %+v, want rebooked as payout 9", it)
+	}
+	if booked.Load() != 2 {
+		t.Errorf("booked %d, want 2", booked.Load())
+	}
+}
+
+func TestBatchPayoutRejectsDuplicateReferences(t *testing.T) {
+	_, err := gotropipay.ParseBatchCSV(strings.NewReader(
+		"beneficiary_id,amount,currency,reference\n1,10.00,EUR,PAY-1\n2,10.00,EUR,PAY-1\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("ParseBatchCSV err = %v, want duplicate on line 3", err)
+	}
+
+	client := newFakeClient(t, http.NotFoundHandler())
+	items := []gotropipay.BatchItem{
+		{Beneficiary: gotropipay.DepositAccount{ID: 1}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "PAY-1"},
+		{Beneficiary: gotropipay.DepositAccount{ID: 2}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "PAY-1"},
+	}
+	path := filepath.Join(t.TempDir(), "batch.journal")
+	if _, err := client.BatchPayout(context.Background(), items, gotropipay.BatchOptions{JournalPath: path, DryRun: true}); err == nil {
+		t.Error("BatchPayout accepted a duplicate reference")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("journal created for a rejected batch: %v", err)
+	}
+}
+
+func TestBatchPayoutInterruptedItemFailingValidation(t *testing.T) {
+	var booked atomic.Int32
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/booking/payout/simulate":
+			http.Error(w, `{"error":"beneficiary disabled"}`, http.StatusBadRequest)
+		case "/booking/payout":
+			booked.Add(1)
+		case "/movements/":
+			writeJSON(w, map[string]interface{}{"items": []interface{}{}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	items := []gotropipay.BatchItem{
+		{Beneficiary: gotropipay.DepositAccount{ID: 1}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "PAY-1"},
+		{Beneficiary: gotropipay.DepositAccount{ID: 2}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "PAY-2"},
+	}
+	path := crashedJournal(t, client, items)
+
+	report, err := client.BatchPayout(context.Background(), items, gotropipay.BatchOptions{
+		JournalPath: path, SkipValidation: true,
+		SecurityCode: func(context.Context, gotropipay.BatchItem) (string, error) { return "1", nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Items[0].Status != gotropipay.BatchItemInDoubt || report.Items[1].Status != gotropipay.BatchItemInvalid {
+		t.Errorf("statuses = %s, %s; want in_doubt, invalid", report.Items[0].Status, report.Items[1].Status)
+	}
+	if report.InDoubt != 1 || report.Invalid != 1 || booked.Load() != 0 {
+		t.Errorf("report = %+v, booked %d", report, booked.Load())
+	}
+}
+
+func TestBatchPayoutCorruptJournal(t *testing.T) {
+	client := newFakeClient(t, http.NotFoundHandler())
+	items := []gotropipay.BatchItem{{Beneficiary: gotropipay.DepositAccount{ID: 1}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "PAY-1"}}
+	path := crashedJournal(t, client, items)
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, append([]byte(strings.Replace(string(data), `"status":"started"}`, `"status":"sta`, 1)), '\n'), 0o600)
+
+	_, err := client.BatchPayout(context.Background(), items, gotropipay.BatchOptions{JournalPath: path, SkipValidation: true, DryRun: true})
+	if err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("err = %v, want corrupt journal", err)
+	}
+}
+
+func TestBatchPayoutConfirm(t *testing.T) {
+	var booked atomic.Int32
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/booking/payout/simulate":
+			writeJSON(w, map[string]interface{}{"amount": 1000, "currency": "EUR", "fee": 25})
+		case "/booking/payout":
+			booked.Add(1)
+			writeJSON(w, map[string]interface{}{"id": 1, "state": "pending"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	items := []gotropipay.BatchItem{{Beneficiary: gotropipay.DepositAccount{ID: 1}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "PAY-1"}}
+
+	var seen gotropipay.Money
+	errTooMuch := errors.New("over budget")
+	report, err := client.BatchPayout(context.Background(), items, gotropipay.BatchOptions{
+		JournalPath: filepath.Join(t.TempDir(), "batch.journal"), SkipValidation: true,
+		SecurityCode: func(context.Context, gotropipay.BatchItem) (string, error) { return "1", nil },
+		Confirm: func(r *gotropipay.BatchReport) error {
+			seen = r.Totals["EUR"]
+			return errTooMuch
+		},
+	})
+	if !errors.Is(err, errTooMuch) || report == nil || booked.Load() != 0 {
+		t.Fatalf("err = %v, booked %d", err, booked.Load())
+	}
+	if seen != gotropipay.NewMoney(1025, "EUR") {
+		t.Errorf("confirm saw totals %v, want 10.25 EUR", seen)
+	}
+}
+
+func TestBatchPayoutConfirmSkipsFailedItems(t *testing.T) {
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/booking/payout/simulate":
+			writeJSON(w, map[string]interface{}{"amount": 1000, "currency": "EUR"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	items := []gotropipay.BatchItem{
+		{Beneficiary: gotropipay.DepositAccount{ID: 1}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "PAY-1"},
+		{Beneficiary: gotropipay.DepositAccount{ID: 2}, Amount: gotropipay.NewMoney(1000, "EUR"), Reference: "PAY-2"},
+	}
+	path := filepath.Join(t.TempDir(), "batch.journal")
+	if _, err := client.BatchPayout(context.Background(), items, gotropipay.BatchOptions{JournalPath: path, SkipValidation: true, DryRun: true}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"index":1,"status":"failed","error":"rejected"}` + "\n")
+	f.Close()
+
+	var seen gotropipay.Money
+	_, err = client.BatchPayout(context.Background(), items, gotropipay.BatchOptions{
+		JournalPath: path, SkipValidation: true,
+		SecurityCode: func(context.Context, gotropipay.BatchItem) (string, error) { return "1", nil },
+		Confirm: func(r *gotropipay.BatchReport) error {
+			seen = r.Totals["EUR"]
+			return errors.New("stop")
+		},
+	})
+	if err == nil {
+		t.Fatal("expected the batch to stop at Confirm")
+	}
+	if seen != gotropipay.NewMoney(1000, "EUR") {
+		t.Errorf("confirm saw totals %v, want 10.00 EUR", seen)
+	}
+}
@@ -0,0 +1,340 @@
+package gotropipay
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tropipay/gotropipay/validate"
+)
+
+// ImportRowStatus is the outcome of one row of a beneficiary import
+type ImportRowStatus string
+
+const (
+	ImportValid     ImportRowStatus = "valid"     // Passed every check; not created (dry run)
+	ImportCreated   ImportRowStatus = "created"   // Beneficiary created
+	ImportExisting  ImportRowStatus = "existing"  // Matches a beneficiary already on the account
+	ImportDuplicate ImportRowStatus = "duplicate" // Repeats an earlier row of the same file
+	ImportInvalid   ImportRowStatus = "invalid"   // Rejected by offline or API validation
+	ImportFailed    ImportRowStatus = "failed"    // CreateDepositAccount returned an error
+	ImportSkipped   ImportRowStatus = "skipped"   // Not attempted before the import was cancelled
+)
+
+// ImportOptions configures ImportBeneficiaries
+type ImportOptions struct {
+	// Mapping maps request fields (the JSON names of CreateDepositAccountRequest,
+	// e.g. "accountNumber", "countryDestinationId") to CSV header names.
+	// Unmapped fields are read from a column with the field's own name.
+	Mapping map[string]string
+	// Catalog, if set, lets country and type columns hold slugs or ISO codes
+	// instead of IDs, and enables phone checks against the country calling code
+	Catalog *Catalog
+	// Currency is sent to ValidateAccountNumber. When empty the country's
+	// default currency from Catalog is used.
+	Currency string
+	// Concurrency bounds parallel API calls (default 4)
+	Concurrency int
+	// SkipRemoteValidation skips the ValidateAccountNumber round-trip
+	SkipRemoteValidation bool
+	// DryRun runs every check without creating anything
+	DryRun bool
+	// OnProgress, if set, is called as each row settles
+	OnProgress func(ImportRowResult)
+}
+
+// ImportRowResult is the outcome of one CSV row
+type ImportRowResult struct {
+	Line           int // 1-based line in the CSV, header included
+	Request        CreateDepositAccountRequest
+	Status         ImportRowStatus
+	DepositAccount *DepositAccount // Created or matching existing beneficiary
+	Error          string
+}
+
+// ImportReport summarises a beneficiary import
+type ImportReport struct {
+	Rows   []ImportRowResult
+	Counts map[ImportRowStatus]int
+}
+
+var importFields = []string{
+	"accountNumber", "firstName", "lastName", "countryDestinationId", "type",
+	"alias", "email", "phone", "address", "swift",
+}
+
+// ImportBeneficiaries creates beneficiaries from a CSV with a header row.
+// Rows are validated offline, deduplicated against existing beneficiaries (by
+// account number and country) and against each other, checked with
+// ValidateAccountNumber and then created with bounded concurrency. Row-level
+// problems are reported in the result; the error is for failures that stop
+// the whole import. If ctx is cancelled mid-import, the report of what was
+// done so far is returned together with ctx.Err().
+func (c *Client) ImportBeneficiaries(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	rows, err := parseBeneficiaryCSV(ctx, r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := c.existingBeneficiaries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list existing beneficiaries: %w", err)
+	}
+
+	// Offline checks and dedupe run sequentially so "first row wins" is stable
+	seen := make(map[string]int)
+	for i := range rows {
+		row := &rows[i]
+		if row.Status != "" {
+			continue
+		}
+		if err := c.checkImportRow(ctx, row.Request, opts); err != nil {
+			row.Status, row.Error = ImportInvalid, err.Error()
+			continue
+		}
+		key := beneficiaryKey(row.Request.AccountNumber, row.Request.CountryDestinationID)
+		if acc, ok := existing[key]; ok {
+			row.Status, row.DepositAccount = ImportExisting, acc
+			continue
+		}
+		if first, ok := seen[key]; ok {
+			row.Status, row.Error = ImportDuplicate, fmt.Sprintf("same account as line %d", first)
+			continue
+		}
+		seen[key] = row.Line
+	}
+
+	forEachBounded(ctx, len(rows), opts.Concurrency, func(i int) {
+		row := &rows[i]
+		if row.Status == "" {
+			c.importRow(ctx, row, opts)
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(*row)
+		}
+	})
+
+	report := &ImportReport{Rows: rows, Counts: make(map[ImportRowStatus]int)}
+	for i := range rows {
+		if rows[i].Status == "" {
+			rows[i].Status = ImportSkipped
+		}
+		report.Counts[rows[i].Status]++
+	}
+	return report, ctx.Err()
+}
+
+func (c *Client) importRow(ctx context.Context, row *ImportRowResult, opts ImportOptions) {
+	if !opts.SkipRemoteValidation {
+		currency := opts.Currency
+		if currency == "" && opts.Catalog != nil {
+			if country, err := opts.Catalog.Country(ctx, strconv.Itoa(row.Request.CountryDestinationID)); err == nil {
+				currency = country.Currency
+			}
+		}
+		val, err := c.ValidateAccountNumber(ctx, ValidateAccountNumberRequest{
+			AccountNumber:        row.Request.AccountNumber,
+			CountryDestinationID: row.Request.CountryDestinationID,
+			Type:                 row.Request.Type,
+			Currency:             currency,
+		})
+		if err != nil {
+			row.Status, row.Error = ImportFailed, err.Error()
+			return
+		}
+		if !val.Valid {
+			row.Status, row.Error = ImportInvalid, fmt.Sprintf("rejected by API: %v", val.ErrorMessage)
+			return
+		}
+	}
+
+	if opts.DryRun {
+		row.Status = ImportValid
+		return
+	}
+	acc, err := c.CreateDepositAccount(ctx, row.Request)
+	if err != nil {
+		row.Status, row.Error = ImportFailed, err.Error()
+		return
+	}
+	row.Status, row.DepositAccount = ImportCreated, acc
+}
+
+// checkImportRow runs the checks that need no API call
+func (c *Client) checkImportRow(ctx context.Context, req CreateDepositAccountRequest, opts ImportOptions) error {
+	if req.AccountNumber == "" {
+		return errors.New("account number is required")
+	}
+	if req.CountryDestinationID == 0 {
+		return errors.New("country is required")
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if req.Phone != "" && opts.Catalog != nil {
+		country, err := opts.Catalog.Country(ctx, strconv.Itoa(req.CountryDestinationID))
+		if err == nil && country.CallingCode > 0 {
+			if _, err := validate.Phone(req.Phone, country.CallingCode); err != nil {
+				return fmt.Errorf("invalid phone: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// existingBeneficiaries indexes every beneficiary on the account by beneficiaryKey
+func (c *Client) existingBeneficiaries(ctx context.Context) (map[string]*DepositAccount, error) {
+	const pageSize = 100
+	index := make(map[string]*DepositAccount)
+	for offset := 0; ; offset += pageSize {
+		page, err := c.ListDepositAccounts(ctx, pageSize, offset, "")
+		if err != nil {
+			return nil, err
+		}
+		for i := range page {
+			acc := &page[i]
+			index[beneficiaryKey(acc.AccountNumber, acc.CountryDestinationID)] = acc
+		}
+		if len(page) < pageSize {
+			return index, nil
+		}
+	}
+}
+
+func beneficiaryKey(accountNumber string, countryID int) string {
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(accountNumber))
+	return fmt.Sprintf("%d|%s", countryID, normalized)
+}
+
+// parseBeneficiaryCSV maps every row to a request. Rows that cannot be mapped
+// come back with status invalid.
+func parseBeneficiaryCSV(ctx context.Context, r io.Reader, opts ImportOptions) ([]ImportRowResult, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	col := make(map[string]int)
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	index := make(map[string]int)
+	for _, field := range importFields {
+		name := field
+		if mapped, ok := opts.Mapping[field]; ok {
+			name = mapped
+		}
+		if i, ok := col[strings.ToLower(name)]; ok {
+			index[field] = i
+		}
+	}
+	for _, required := range []string{"accountNumber", "countryDestinationId"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("missing CSV column for %s", required)
+		}
+	}
+
+	var rows []ImportRowResult
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Quoted fields may span lines, so ask the reader where the record starts
+		line, _ := cr.FieldPos(0)
+		get := func(field string) string {
+			if i, ok := index[field]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+
+		row := ImportRowResult{Line: line}
+		row.Request = CreateDepositAccountRequest{
+			AccountNumber: get("accountNumber"),
+			FirstName:     get("firstName"),
+			LastName:      get("lastName"),
+			Alias:         get("alias"),
+			Email:         get("email"),
+			Phone:         get("phone"),
+			Address:       get("address"),
+			Swift:         get("swift"),
+		}
+		row.Request.CountryDestinationID, err = resolveCatalogID(ctx, get("countryDestinationId"), opts.Catalog, "country")
+		if err == nil && get("type") != "" {
+			row.Request.Type, err = resolveCatalogID(ctx, get("type"), opts.Catalog, "type")
+		}
+		if err != nil {
+			row.Status, row.Error = ImportInvalid, err.Error()
+		}
+		rows = append(rows, row)
+	}
+}
+
+// resolveCatalogID accepts a numeric ID or, with a catalog, a slug or code
+func resolveCatalogID(ctx context.Context, value string, cat *Catalog, kind string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, nil
+	}
+	if cat == nil {
+		return 0, fmt.Errorf("%s %q is not an ID and no catalog was given", kind, value)
+	}
+	if kind == "country" {
+		country, err := cat.Country(ctx, value)
+		if err != nil {
+			return 0, err
+		}
+		return country.ID, nil
+	}
+	t, err := cat.DepositAccountType(ctx, value)
+	if err != nil {
+		return 0, err
+	}
+	return t.ID, nil
+}
+
+// WriteCSV renders one result row per input row, in input order
+func (r *ImportReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"line", "account_number", "country_id", "status", "beneficiary_id", "error"}); err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		id := ""
+		if row.DepositAccount != nil {
+			id = strconv.Itoa(row.DepositAccount.ID)
+		}
+		rec := []string{
+			strconv.Itoa(row.Line), row.Request.AccountNumber, strconv.Itoa(row.Request.CountryDestinationID),
+			string(row.Status), id, row.Error,
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,113 @@
+package gotropipay_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestImportBeneficiaries(t *testing.T) {
+	var created atomic.Int32
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/depositaccounts/":
+			writeJSON(w, map[string]interface{}{"items": []gotropipay.DepositAccount{
+				{ID: 10, AccountNumber: "ES9121000418450200051332", CountryDestinationID: 1},
+			}})
+		case r.URL.Path == "/depositaccounts/validateaccountnumber":
+			writeJSON(w, gotropipay.ValidateAccountNumberResponse{Valid: true})
+		case r.Method == "POST" && r.URL.Path == "/depositaccounts/":
+			writeJSON(w, gotropipay.DepositAccount{ID: 100 + int(created.Add(1))})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	csv := `iban,country,first_name
+ES91 2100 0418 4502 0005 1332,spain,Existing
+DE89370400440532013000,germany,New
+DE89 3704 0044 0532 0130 00,DE,Repeated
+DE89370400440532013001,DE,BadChecksum
+`
+	opts := gotropipay.ImportOptions{
+		Mapping: map[string]string{"accountNumber": "iban", "countryDestinationId": "country", "firstName": "first_name"},
+		Catalog: syntheticCatalog(t),
+		DryRun:  true,
+	}
+
+	report, err := client.ImportBeneficiaries(context.Background(), strings.NewReader(csv), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []gotropipay.ImportRowStatus{
+		gotropipay.ImportExisting, gotropipay.ImportValid, gotropipay.ImportDuplicate, gotropipay.ImportInvalid,
+	}
+	for i, row := range report.Rows {
+		if row.Status != want[i] {
+			t.Errorf("line %d: status %q (%s), want %q", row.Line, row.Status, row.Error, want[i])
+		}
+	}
+	if created.Load() != 0 {
+		t.Fatal("dry run created beneficiaries")
+	}
+
+	opts.DryRun = false
+	report, err = client.ImportBeneficiaries(context.Background(), strings.NewReader(csv), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Counts[gotropipay.ImportCreated] != 1 || created.Load() != 1 {
+		t.Errorf("created %d (%v), want 1", created.Load(), report.Counts)
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteCSV(&out); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 5 {
+		t.Errorf("results file has %d lines, want 5:\n%s", lines, out.String())
+	}
+}
+
+func TestImportBeneficiariesLinesAndCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/depositaccounts/":
+			writeJSON(w, map[string]interface{}{"items": []gotropipay.DepositAccount{}})
+		case r.Method == "POST" && r.URL.Path == "/depositaccounts/":
+			// The caller gives up during the first creation
+			cancel()
+			writeJSON(w, gotropipay.DepositAccount{ID: 100})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	csv := "accountNumber,countryDestinationId,address\n" +
+		"DE89370400440532013000,1,\"Line one\nLine two\"\n" +
+		"ES9121000418450200051332,1,Street\n" +
+		"GB82WEST12345698765432,1,Road\n"
+	report, err := client.ImportBeneficiaries(ctx, strings.NewReader(csv), gotropipay.ImportOptions{
+		SkipRemoteValidation: true,
+		Concurrency:          1,
+	})
+	if !errors.Is(err, context.Canceled) || report == nil {
+		t.Fatalf("err = %v, report %v; want partial report and context.Canceled", err, report)
+	}
+	for i, want := range []int{2, 4, 5} {
+		if report.Rows[i].Line != want {
+			t.Errorf("row %d: line %d, want %d", i, report.Rows[i].Line, want)
+		}
+	}
+	if report.Rows[0].Status == gotropipay.ImportSkipped || report.Counts[gotropipay.ImportSkipped] != 2 {
+		t.Errorf("rows = %+v, counts %v", report.Rows, report.Counts)
+	}
+}
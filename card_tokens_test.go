@@ -0,0 +1,76 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestCardTokenAndCharge(t *testing.T) {
+	var key string
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/movements/":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"items":[
+				{"id":1,"reference":"SUB-1","state":"completed","cardToken":"tok_123"},
+				{"id":2,"reference":"ONE-OFF","state":"completed"}]}`))
+		case "/paymentcards/charge":
+			key = r.Header.Get("Idempotency-Key")
+			writeJSON(w, map[string]interface{}{"id": 9, "reference": "SUB-1-2", "state": "Failed", "amount": 999, "currency": "EUR", "errorMessage": "insufficient funds"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	tok, err := client.CardTokenForPayment(ctx, "SUB-1")
+	if err != nil || tok.Token != "tok_123" {
+		t.Fatalf("CardTokenForPayment = %+v, %v", tok, err)
+	}
+	if _, err := client.CardTokenForPayment(ctx, "ONE-OFF"); !errors.Is(err, gotropipay.ErrNoCardToken) {
+		t.Errorf("err = %v, want ErrNoCardToken", err)
+	}
+
+	ch, err := client.ChargeSavedCard(ctx, gotropipay.ChargeRequest{
+		Token: tok.Token, Amount: gotropipay.NewMoney(999, "EUR"), Reference: "SUB-1-2", IdempotencyKey: "k1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch.Succeeded() || ch.State != gotropipay.MovementStateFailed || ch.DeclineReason != "insufficient funds" || key != "k1" {
+		t.Errorf("charge = %+v, key %q", ch, key)
+	}
+}
+
+func TestCreatePaylinkSendsNoCardFields(t *testing.T) {
+	var body map[string]interface{}
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		writeJSON(w, map[string]interface{}{"id": "pl-1", "reference": body["reference"], "shortUrl": "https://tppay.me/x"})
+	}))
+	ctx := context.Background()
+
+	card, err := client.CreatePaylink(ctx, gotropipay.CreatePaylinkRequest{
+		Reference: "SUB-1", Concept: "Pro plan", Amount: 999, Currency: "EUR", SaveToken: true,
+	})
+	if err != nil || card.ID != "pl-1" {
+		t.Fatalf("CreatePaylink = %+v, %v", card, err)
+	}
+	for _, k := range []string{"number", "cvc", "expiryMonth", "expiryYear", "holderName"} {
+		if _, ok := body[k]; ok {
+			t.Errorf("paylink payload carries card field %q", k)
+		}
+	}
+	if body["saveToken"] != true || body["amount"] != float64(999) {
+		t.Errorf("payload = %v", body)
+	}
+
+	if _, err := client.CreatePaylink(ctx, gotropipay.CreatePaylinkRequest{Reference: "X", Currency: "EUR"}); err == nil {
+		t.Error("expected error for zero amount")
+	}
+}
@@ -0,0 +1,305 @@
+package gotropipay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotInCatalog is returned when a catalog lookup finds no entry
+var ErrNotInCatalog = errors.New("not found in catalog")
+
+// DefaultCatalogRefresh is how often a live Catalog refetches reference data
+const DefaultCatalogRefresh = 24 * time.Hour
+
+// catalogRetryAfter is how long lookups keep using stale data after a failed
+// refresh before trying again
+const catalogRetryAfter = time.Minute
+
+// PaymentReason is a purpose code accepted by paylinks and payouts (ReasonID)
+type PaymentReason struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// DepositAccountType is a kind of beneficiary account (DepositAccount.Type)
+type DepositAccountType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// Currency describes a currency supported by the platform
+type Currency struct {
+	Code     string `json:"code"` // ISO 4217
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol,omitempty"`
+	Decimals int    `json:"decimals"`
+}
+
+// ListCountries retrieves the destination countries for beneficiaries
+func (c *Client) ListCountries(ctx context.Context) ([]CountryDestination, error) {
+	var countries []CountryDestination
+	err := c.Request(ctx, "GET", "/countries/destinations", nil, &countries)
+	if err != nil {
+		return nil, err
+	}
+	return countries, nil
+}
+
+// ListPaymentReasons retrieves the payment purpose codes
+func (c *Client) ListPaymentReasons(ctx context.Context) ([]PaymentReason, error) {
+	var reasons []PaymentReason
+	err := c.Request(ctx, "GET", "/paymentreasons/", nil, &reasons)
+	if err != nil {
+		return nil, err
+	}
+	return reasons, nil
+}
+
+// ListDepositAccountTypes retrieves the supported beneficiary account types
+func (c *Client) ListDepositAccountTypes(ctx context.Context) ([]DepositAccountType, error) {
+	var types []DepositAccountType
+	err := c.Request(ctx, "GET", "/depositaccounts/types", nil, &types)
+	if err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// ListCurrencies retrieves the supported currencies
+func (c *Client) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	var currencies []Currency
+	err := c.Request(ctx, "GET", "/currencies/", nil, &currencies)
+	if err != nil {
+		return nil, err
+	}
+	return currencies, nil
+}
+
+// catalogData is one full load of the reference tables, and the snapshot
+// format written by cmd/catalogsnapshot
+type catalogData struct {
+	Countries           []CountryDestination `json:"countries"`
+	PaymentReasons      []PaymentReason      `json:"paymentReasons"`
+	DepositAccountTypes []DepositAccountType `json:"depositAccountTypes"`
+	Currencies          []Currency           `json:"currencies"`
+}
+
+// Catalog caches reference data and resolves human keys (slugs, ISO codes,
+// names) to the IDs the API expects. A live catalog refetches everything once
+// the refresh interval has passed; concurrent lookups share one refresh. If a
+// refresh fails, stale data is kept, lookups do not retry for a minute, and
+// the error is available from LastError.
+type Catalog struct {
+	client  *Client // nil for an offline snapshot
+	refresh time.Duration
+
+	mu       sync.RWMutex
+	data     *catalogData
+	loadedAt time.Time
+	lastErr  error
+	retryAt  time.Time     // No automatic refresh before this, after a failure
+	inflight *catalogFetch // Refresh in progress, shared by concurrent callers
+}
+
+// catalogFetch is one refresh that concurrent callers wait on
+type catalogFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// NewCatalog returns a catalog backed by the API. Data is loaded on first use.
+// A refresh of zero uses DefaultCatalogRefresh.
+func (c *Client) NewCatalog(refresh time.Duration) *Catalog {
+	if refresh <= 0 {
+		refresh = DefaultCatalogRefresh
+	}
+	return &Catalog{client: c, refresh: refresh}
+}
+
+// OfflineCatalog returns a catalog backed by a snapshot written by
+// cmd/catalogsnapshot. It never touches the network; its IDs are only as
+// current as the snapshot.
+func OfflineCatalog(r io.Reader) (*Catalog, error) {
+	var data catalogData
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("invalid catalog snapshot: %w", err)
+	}
+	return &Catalog{data: &data, loadedAt: time.Now()}, nil
+}
+
+// Refresh reloads every table from the API. A call made while another
+// refresh is running waits for that one instead of starting its own.
+func (cat *Catalog) Refresh(ctx context.Context) error {
+	if cat.client == nil {
+		return nil
+	}
+
+	cat.mu.Lock()
+	f := cat.inflight
+	if f == nil {
+		// The fetch is shared, so it runs detached from this caller's
+		// cancellation; each caller stops waiting on its own ctx
+		f = &catalogFetch{done: make(chan struct{})}
+		cat.inflight = f
+		go func() {
+			f.err = cat.fetch(context.WithoutCancel(ctx))
+			cat.mu.Lock()
+			cat.inflight = nil
+			cat.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	cat.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetch loads every table and stores the result or the error
+func (cat *Catalog) fetch(ctx context.Context) error {
+	var (
+		data catalogData
+		err  error
+	)
+	if data.Countries, err = cat.client.ListCountries(ctx); err == nil {
+		if data.PaymentReasons, err = cat.client.ListPaymentReasons(ctx); err == nil {
+			if data.DepositAccountTypes, err = cat.client.ListDepositAccountTypes(ctx); err == nil {
+				data.Currencies, err = cat.client.ListCurrencies(ctx)
+			}
+		}
+	}
+
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	cat.lastErr = err
+	if err != nil {
+		cat.retryAt = time.Now().Add(catalogRetryAfter)
+		return fmt.Errorf("failed to refresh catalog: %w", err)
+	}
+	cat.data = &data
+	cat.loadedAt = time.Now()
+	cat.retryAt = time.Time{}
+	return nil
+}
+
+// LastError returns the error from the most recent refresh, if any
+func (cat *Catalog) LastError() error {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	return cat.lastErr
+}
+
+// load returns current data, refreshing it when missing or stale
+func (cat *Catalog) load(ctx context.Context) (*catalogData, error) {
+	cat.mu.RLock()
+	data, loadedAt, retryAt, lastErr := cat.data, cat.loadedAt, cat.retryAt, cat.lastErr
+	cat.mu.RUnlock()
+
+	if cat.client != nil && (data == nil || time.Since(loadedAt) >= cat.refresh) {
+		if time.Now().Before(retryAt) {
+			// Backing off after a failure: serve stale data or the last error
+			if data == nil {
+				return nil, fmt.Errorf("failed to refresh catalog: %w", lastErr)
+			}
+			return data, nil
+		}
+		if err := cat.Refresh(ctx); err != nil && data == nil {
+			return nil, err
+		}
+		cat.mu.RLock()
+		data = cat.data
+		cat.mu.RUnlock()
+	}
+	return data, nil
+}
+
+// Countries returns every destination country
+func (cat *Catalog) Countries(ctx context.Context) ([]CountryDestination, error) {
+	data, err := cat.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return data.Countries, nil
+}
+
+// Country finds a country by slug ("spain"), ISO code ("ES"), name or numeric ID
+func (cat *Catalog) Country(ctx context.Context, key string) (*CountryDestination, error) {
+	data, err := cat.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range data.Countries {
+		if catalogKeyMatches(key, c.ID, c.Slug, c.Code, c.Name) {
+			return &data.Countries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("country %q: %w", key, ErrNotInCatalog)
+}
+
+// PaymentReason finds a payment reason by slug, name or numeric ID
+func (cat *Catalog) PaymentReason(ctx context.Context, key string) (*PaymentReason, error) {
+	data, err := cat.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range data.PaymentReasons {
+		if catalogKeyMatches(key, r.ID, r.Slug, r.Name) {
+			return &data.PaymentReasons[i], nil
+		}
+	}
+	return nil, fmt.Errorf("payment reason %q: %w", key, ErrNotInCatalog)
+}
+
+// DepositAccountType finds a beneficiary account type by slug, name or numeric ID
+func (cat *Catalog) DepositAccountType(ctx context.Context, key string) (*DepositAccountType, error) {
+	data, err := cat.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range data.DepositAccountTypes {
+		if catalogKeyMatches(key, t.ID, t.Slug, t.Name) {
+			return &data.DepositAccountTypes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("deposit account type %q: %w", key, ErrNotInCatalog)
+}
+
+// Currency finds a currency by ISO 4217 code
+func (cat *Catalog) Currency(ctx context.Context, code string) (*Currency, error) {
+	data, err := cat.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range data.Currencies {
+		if strings.EqualFold(c.Code, code) {
+			return &data.Currencies[i], nil
+		}
+	}
+	return nil, fmt.Errorf("currency %q: %w", code, ErrNotInCatalog)
+}
+
+func catalogKeyMatches(key string, id int, names ...string) bool {
+	key = strings.TrimSpace(key)
+	if n, err := strconv.Atoi(key); err == nil {
+		return n == id
+	}
+	for _, name := range names {
+		if name != "" && strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
+}
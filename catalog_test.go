@@ -0,0 +1,189 @@
+package gotropipay_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// syntheticCatalog loads the made-up snapshot in testdata; its IDs do not
+// match the real API
+func syntheticCatalog(t *testing.T) *gotropipay.Catalog {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "synthetic_catalog.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cat, err := gotropipay.OfflineCatalog(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cat
+}
+
+func TestOfflineCatalogLookups(t *testing.T) {
+	cat := syntheticCatalog(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"spain", "ES", "Spain", "1"} {
+		c, err := cat.Country(ctx, key)
+		if err != nil {
+			t.Fatalf("Country(%q): %v", key, err)
+		}
+		if c.ID != 1 || c.CallingCode != 34 {
+			t.Errorf("Country(%q) = %+v", key, c)
+		}
+	}
+	if _, err := cat.Currency(ctx, "eur"); err != nil {
+		t.Error(err)
+	}
+	if _, err := cat.Country(ctx, "atlantis"); !errors.Is(err, gotropipay.ErrNotInCatalog) {
+		t.Errorf("err = %v, want ErrNotInCatalog", err)
+	}
+	if _, err := gotropipay.OfflineCatalog(strings.NewReader("not json")); err == nil {
+		t.Error("expected error for an invalid snapshot")
+	}
+}
+
+func TestCatalogCachesAndKeepsStaleData(t *testing.T) {
+	var calls atomic.Int32
+	var fail atomic.Bool
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/countries/destinations":
+			calls.Add(1)
+			writeJSON(w, []gotropipay.CountryDestination{{ID: 77, Name: "Cuba", Slug: "cuba", Code: "CU"}})
+		case "/paymentreasons/":
+			writeJSON(w, []gotropipay.PaymentReason{{ID: 4, Slug: "salary"}})
+		case "/depositaccounts/types", "/currencies/":
+			writeJSON(w, []interface{}{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	cat := client.NewCatalog(0)
+	if c, err := cat.Country(ctx, "cu"); err != nil || c.ID != 77 {
+		t.Fatalf("Country = %+v, %v", c, err)
+	}
+	if r, err := cat.PaymentReason(ctx, "salary"); err != nil || r.ID != 4 {
+		t.Fatalf("PaymentReason = %+v, %v", r, err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("countries fetched %d times, want 1", n)
+	}
+
+	fail.Store(true)
+	if err := cat.Refresh(ctx); err == nil {
+		t.Fatal("expected refresh error")
+	}
+	if _, err := cat.Country(ctx, "cuba"); err != nil {
+		t.Errorf("stale lookup failed: %v", err)
+	}
+	if cat.LastError() == nil {
+		t.Error("LastError should report the failed refresh")
+	}
+}
+
+func TestCatalogCoalescesAndBacksOff(t *testing.T) {
+	var calls atomic.Int32
+	var fail atomic.Bool
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/countries/destinations":
+			calls.Add(1)
+			if fail.Load() {
+				http.Error(w, "down", http.StatusServiceUnavailable)
+				return
+			}
+			time.Sleep(20 * time.Millisecond) // let concurrent lookups pile up
+			writeJSON(w, []gotropipay.CountryDestination{{ID: 77, Slug: "cuba"}})
+		case "/paymentreasons/", "/depositaccounts/types", "/currencies/":
+			writeJSON(w, []interface{}{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	cat := client.NewCatalog(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cat.Country(ctx, "cuba"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("countries fetched %d times by concurrent lookups, want 1", n)
+	}
+
+	// A catalog whose first load failed does not hammer the API on every lookup
+	fail.Store(true)
+	calls.Store(0)
+	down := client.NewCatalog(0)
+	for i := 0; i < 5; i++ {
+		if _, err := down.Country(ctx, "cuba"); err == nil {
+			t.Fatal("expected error while the API is down")
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("countries fetched %d times after a failure, want 1", n)
+	}
+}
+
+func TestCatalogRefreshOutlivesTheCallerThatStartedIt(t *testing.T) {
+	release := make(chan struct{})
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/countries/destinations":
+			<-release
+			writeJSON(w, []gotropipay.CountryDestination{{ID: 77, Slug: "cuba"}})
+		case "/paymentreasons/", "/depositaccounts/types", "/currencies/":
+			writeJSON(w, []interface{}{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	cat := client.NewCatalog(0)
+
+	first, cancel := context.WithCancel(context.Background())
+	started := make(chan error, 1)
+	go func() { started <- cat.Refresh(first) }()
+	time.Sleep(10 * time.Millisecond)
+
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := cat.Country(context.Background(), "cuba")
+		waiter <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	if err := <-started; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller err = %v, want context.Canceled", err)
+	}
+	close(release)
+	if err := <-waiter; err != nil {
+		t.Errorf("waiting lookup failed with the first caller's cancellation: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package gotropipay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrCheckoutUnknownPayment is set on CheckoutResult.Err when the return URL
+// does not identify a paylink of this account
+var ErrCheckoutUnknownPayment = errors.New("checkout return does not match a known paylink")
+
+// ErrCheckoutAmountMismatch is set on CheckoutResult.Err when the completed
+// movement does not carry the paylink's amount and currency
+var ErrCheckoutAmountMismatch = errors.New("payment does not match the paylink amount")
+
+// CheckoutResult is the verified outcome of a customer returning from checkout
+type CheckoutResult struct {
+	Reference string
+	State     MovementState // Confirmed server-side; empty if the paylink is open and no movement exists yet
+	Card      *PaymentCard
+	Movement  *Movement
+	Err       error // Set when the payment could not be verified
+}
+
+// Paid reports whether the payment is confirmed as completed
+func (r *CheckoutResult) Paid() bool {
+	return r.Err == nil && r.State == MovementStateCompleted
+}
+
+// CheckoutFunc renders the page for a returning customer
+type CheckoutFunc func(w http.ResponseWriter, r *http.Request, result *CheckoutResult)
+
+// CheckoutHandler serves the URLSuccess and URLFailed return URLs of
+// paylinks. It ignores which URL the customer landed on: the paylink is
+// looked up with GetPaymentCard and its state and movement checked, including
+// the amount paid, so visiting the success URL by hand cannot fake a payment.
+//
+// Include the paylink reference in both URLs when creating it, e.g.
+// "https://shop.example/checkout/return?reference=ORDER-1".
+type CheckoutHandler struct {
+	client *Client
+
+	OnSuccess CheckoutFunc // Payment completed
+	OnFailure CheckoutFunc // Failed, cancelled, unverifiable or (without OnPending) still pending
+	OnPending CheckoutFunc // Optional: no terminal state yet
+
+	// ReferenceParams are the query parameters searched for the reference
+	// (default "reference", "ref")
+	ReferenceParams []string
+	// IDParams are the query parameters searched for a paylink ID, which
+	// avoids a listing lookup (default "paymentcardId", "id")
+	IDParams []string
+	// Wait, if positive, keeps polling a pending payment for up to this long
+	// before answering, for banks that confirm a few seconds late
+	Wait time.Duration
+}
+
+// NewCheckoutHandler returns a handler for paylink return URLs. It panics if
+// onSuccess or onFailure is nil.
+func (c *Client) NewCheckoutHandler(onSuccess, onFailure CheckoutFunc) *CheckoutHandler {
+	if onSuccess == nil || onFailure == nil {
+		panic("gotropipay: NewCheckoutHandler needs both onSuccess and onFailure")
+	}
+	return &CheckoutHandler{
+		client:          c,
+		OnSuccess:       onSuccess,
+		OnFailure:       onFailure,
+		ReferenceParams: []string{"reference", "ref"},
+		IDParams:        []string{"paymentcardId", "id"},
+	}
+}
+
+// ServeHTTP verifies the payment and calls the matching callback
+func (h *CheckoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result := h.Verify(r.Context(), r)
+	switch {
+	case result.Paid():
+		h.OnSuccess(w, r, result)
+	case result.Err == nil && !result.State.IsTerminal() && h.OnPending != nil:
+		h.OnPending(w, r, result)
+	default:
+		h.OnFailure(w, r, result)
+	}
+}
+
+// Verify extracts the paylink from a return request and confirms its state
+// with the API. It is what ServeHTTP uses, exposed for custom routing.
+func (h *CheckoutHandler) Verify(ctx context.Context, r *http.Request) *CheckoutResult {
+	_ = r.ParseForm()
+	reference := firstParam(r, h.ReferenceParams)
+	id := firstParam(r, h.IDParams)
+	result := &CheckoutResult{Reference: reference}
+
+	if id == "" && reference == "" {
+		result.Err = fmt.Errorf("%w: no reference in return URL", ErrCheckoutUnknownPayment)
+		return result
+	}
+	if id == "" {
+		var err error
+		if id, err = h.client.paymentCardIDByReference(ctx, reference); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	card, err := h.client.GetPaymentCard(ctx, id)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if reference != "" && card.Reference != reference {
+		result.Err = fmt.Errorf("%w: paylink %s has reference %q", ErrCheckoutUnknownPayment, id, card.Reference)
+		return result
+	}
+	result.Card, result.Reference = card, card.Reference
+
+	mov, err := h.client.findPaymentMovement(ctx, card.Reference, time.Time{})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	// Keep polling while pending, or while the bank has not reported yet
+	if card.State != PaymentCardStatePaid && (mov == nil || !MovementState(mov.State).IsTerminal()) && h.Wait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, h.Wait)
+		defer cancel()
+		res, err := h.client.WaitForPayment(waitCtx, id, &WaitOptions{InitialInterval: time.Second, MaxInterval: 2 * time.Second})
+		if err == nil || errors.Is(err, ErrPaymentCardExpired) {
+			result.Card = res.Card
+			if res.Movement != nil {
+				mov = res.Movement
+			}
+		}
+	}
+
+	if mov != nil {
+		result.Movement = mov
+		result.State = MovementState(strings.ToLower(mov.State))
+	}
+	if result.Card.State == PaymentCardStatePaid {
+		// The paylink itself is authoritative; its movement may not be listed yet
+		result.State = MovementStateCompleted
+	}
+	if mov != nil && MovementState(strings.ToLower(mov.State)) == MovementStateCompleted &&
+		(mov.Amount != result.Card.Amount || !strings.EqualFold(mov.Currency, result.Card.Currency)) {
+		result.Err = fmt.Errorf("%w: paid %s, paylink %s", ErrCheckoutAmountMismatch,
+			NewMoney(mov.Amount, mov.Currency), NewMoney(result.Card.Amount, result.Card.Currency))
+	}
+	return result
+}
+
+// paymentCardIDByReference finds the most recently created paylink with reference
+func (c *Client) paymentCardIDByReference(ctx context.Context, reference string) (string, error) {
+	cards, err := c.ListPaymentCards(ctx)
+	if err != nil {
+		return "", err
+	}
+	var found *PaymentCard
+	for i := range cards {
+		if cards[i].Reference == reference && (found == nil || cards[i].CreatedAt > found.CreatedAt) {
+			found = &cards[i]
+		}
+	}
+	if found == nil {
+		return "", fmt.Errorf("%w: reference %q", ErrCheckoutUnknownPayment, reference)
+	}
+	return found.ID, nil
+}
+
+func firstParam(r *http.Request, names []string) string {
+	for _, name := range names {
+		if v := strings.TrimSpace(r.Form.Get(name)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+package gotropipay_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestCheckoutHandlerVerifiesState(t *testing.T) {
+	cards := []gotropipay.PaymentCard{
+		{ID: "pc-paid", Reference: "ORDER-1"},
+		{ID: "pc-open", Reference: "ORDER-2"},
+	}
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/paymentcards":
+			writeJSON(w, cards)
+		case "/paymentcards/pc-paid":
+			writeJSON(w, cards[0])
+		case "/paymentcards/pc-open":
+			writeJSON(w, cards[1])
+		case "/movements/":
+			var items []gotropipay.Movement
+			if strings.Contains(r.URL.Query().Get("query"), "ORDER-1") {
+				items = []gotropipay.Movement{{ID: 1, Reference: "ORDER-1", State: "completed"}}
+			}
+			writeJSON(w, gotropipay.ListMovementsResponse{Items: items})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	h := client.NewCheckoutHandler(
+		func(w http.ResponseWriter, r *http.Request, res *gotropipay.CheckoutResult) {
+			io.WriteString(w, "paid "+res.Reference)
+		},
+		func(w http.ResponseWriter, r *http.Request, res *gotropipay.CheckoutResult) {
+			msg := "not paid " + res.Reference
+			if errors.Is(res.Err, gotropipay.ErrCheckoutUnknownPayment) {
+				msg = "unknown"
+			}
+			io.WriteString(w, msg)
+		},
+	)
+
+	cases := map[string]string{
+		"/checkout/success?reference=ORDER-1":                 "paid ORDER-1",
+		"/checkout/success?reference=ORDER-2":                 "not paid ORDER-2", // spoofed success
+		"/checkout/failed?paymentcardId=pc-paid":              "paid ORDER-1",
+		"/checkout/success?reference=ORDER-9":                 "unknown",
+		"/checkout/success?paymentcardId=pc-open&ref=ORDER-1": "unknown", // mismatched pair
+	}
+	for url, want := range cases {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		if got := rec.Body.String(); got != want {
+			t.Errorf("%s: got %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestCheckoutHandlerPrefersCompletedAndWaits(t *testing.T) {
+	var polls atomic.Int32
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/paymentcards/pc-retried":
+			writeJSON(w, gotropipay.PaymentCard{ID: "pc-retried", Reference: "ORDER-3"})
+		case "/paymentcards/pc-late":
+			writeJSON(w, gotropipay.PaymentCard{ID: "pc-late", Reference: "ORDER-4"})
+		case "/movements/":
+			var items []gotropipay.Movement
+			switch q := r.URL.Query().Get("query"); {
+			case strings.Contains(q, "ORDER-3"):
+				// The first attempt was declined, the retry went through
+				items = []gotropipay.Movement{{ID: 1, Reference: "ORDER-3", State: "failed"}, {ID: 2, Reference: "ORDER-3", State: "Completed"}}
+			case strings.Contains(q, "ORDER-4") && polls.Add(1) > 1:
+				items = []gotropipay.Movement{{ID: 3, Reference: "ORDER-4", State: "completed"}}
+			}
+			writeJSON(w, gotropipay.ListMovementsResponse{Items: items})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	paid := func(w http.ResponseWriter, r *http.Request, res *gotropipay.CheckoutResult) {
+		io.WriteString(w, "paid")
+	}
+	failed := func(w http.ResponseWriter, r *http.Request, res *gotropipay.CheckoutResult) {
+		io.WriteString(w, "not paid")
+	}
+	h := client.NewCheckoutHandler(paid, failed)
+	h.Wait = 5 * time.Second
+
+	for _, url := range []string{"/return?paymentcardId=pc-retried", "/return?paymentcardId=pc-late"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		if got := rec.Body.String(); got != "paid" {
+			t.Errorf("%s: got %q, want paid", url, got)
+		}
+	}
+}
+
+func TestCheckoutHandlerChecksPaylink(t *testing.T) {
+	cards := map[string]gotropipay.PaymentCard{
+		"pc-paid":  {ID: "pc-paid", Reference: "ORDER-5", Amount: 1500, Currency: "EUR", State: gotropipay.PaymentCardStatePaid},
+		"pc-short": {ID: "pc-short", Reference: "ORDER-6", Amount: 1500, Currency: "EUR", State: gotropipay.PaymentCardStateActive},
+	}
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/paymentcards/"):
+			writeJSON(w, cards[strings.TrimPrefix(r.URL.Path, "/paymentcards/")])
+		case r.URL.Path == "/movements/":
+			var items []gotropipay.Movement
+			if strings.Contains(r.URL.Query().Get("query"), "ORDER-6") {
+				items = []gotropipay.Movement{{ID: 6, Reference: "ORDER-6", State: "completed", Amount: 15, Currency: "EUR"}}
+			}
+			writeJSON(w, gotropipay.ListMovementsResponse{Items: items})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	var failure error
+	h := client.NewCheckoutHandler(
+		func(w http.ResponseWriter, r *http.Request, res *gotropipay.CheckoutResult) {
+			io.WriteString(w, "paid")
+		},
+		func(w http.ResponseWriter, r *http.Request, res *gotropipay.CheckoutResult) {
+			failure = res.Err
+			io.WriteString(w, "not paid")
+		},
+	)
+
+	// Paid paylink whose movement is not listed yet
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/return?paymentcardId=pc-paid", nil))
+	if got := rec.Body.String(); got != "paid" {
+		t.Errorf("paid paylink: got %q", got)
+	}
+
+	// A completed movement for less than the paylink amount
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/return?paymentcardId=pc-short", nil))
+	if got := rec.Body.String(); got != "not paid" || !errors.Is(failure, gotropipay.ErrCheckoutAmountMismatch) {
+		t.Errorf("short payment: got %q, err %v", got, failure)
+	}
+}
+
+func TestNewCheckoutHandlerRequiresCallbacks(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for a nil callback")
+		}
+	}()
+	client := newFakeClient(t, http.NotFoundHandler())
+	client.NewCheckoutHandler(func(http.ResponseWriter, *http.Request, *gotropipay.CheckoutResult) {}, nil)
+}
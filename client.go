@@ -2,6 +2,7 @@ package gotropipay
 
 import (
 	"net/http"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,6 +12,17 @@ type Client struct {
 	clientSecret string
 	baseURL      string
 	httpClient   *http.Client
+	graphQLPath  string
+
+	// gqlBatchUnsupported is set once the server rejects a batched GraphQL request
+	gqlBatchUnsupported atomic.Bool
+
+	// rates caches exchange rates for rateTTL
+	rates   rateCache
+	rateTTL time.Duration
+
+	// securityCodes supplies codes for sensitive operations called without one
+	securityCodes SecurityCodeProvider
 
 	// auth holds the authentication state and logic
 	auth *authenticator
@@ -22,6 +34,8 @@ func NewClient(clientID, clientSecret string, opts ...Option) *Client {
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		baseURL:      string(ProductionEnv), // default is production
+		graphQLPath:  DefaultGraphQLPath,
+		rateTTL:      DefaultRateCacheTTL,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
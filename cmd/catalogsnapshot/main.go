@@ -0,0 +1,68 @@
+// Command catalogsnapshot fetches the reference tables from the API and writes
+// a snapshot for gotropipay.OfflineCatalog. Run it with TROPIPAY_CLIENT_ID and
+// TROPIPAY_CLIENT_SECRET set (a .env file is read if present).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/tropipay/gotropipay"
+)
+
+func main() {
+	out := flag.String("o", "catalog_snapshot.json", "output file")
+	sandbox := flag.Bool("sandbox", false, "read from the sandbox instead of production")
+	flag.Parse()
+
+	_ = godotenv.Load()
+	clientID := os.Getenv("TROPIPAY_CLIENT_ID")
+	clientSecret := os.Getenv("TROPIPAY_CLIENT_SECRET")
+	if clientID == "" || clientSecret == "" {
+		log.Fatal("TROPIPAY_CLIENT_ID and TROPIPAY_CLIENT_SECRET must be set")
+	}
+
+	env := gotropipay.ProductionEnv
+	if *sandbox {
+		env = gotropipay.SandboxEnv
+	}
+	client := gotropipay.NewClient(clientID, clientSecret, gotropipay.WithEnvironment(env))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// Same keys as the catalog's snapshot format
+	var snapshot struct {
+		Countries           []gotropipay.CountryDestination `json:"countries"`
+		PaymentReasons      []gotropipay.PaymentReason      `json:"paymentReasons"`
+		DepositAccountTypes []gotropipay.DepositAccountType `json:"depositAccountTypes"`
+		Currencies          []gotropipay.Currency           `json:"currencies"`
+	}
+	var err error
+	if snapshot.Countries, err = client.ListCountries(ctx); err != nil {
+		log.Fatal(err)
+	}
+	if snapshot.PaymentReasons, err = client.ListPaymentReasons(ctx); err != nil {
+		log.Fatal(err)
+	}
+	if snapshot.DepositAccountTypes, err = client.ListDepositAccountTypes(ctx); err != nil {
+		log.Fatal(err)
+	}
+	if snapshot.Currencies, err = client.ListCurrencies(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d countries, %d payment reasons, %d account types and %d currencies to %s",
+		len(snapshot.Countries), len(snapshot.PaymentReasons), len(snapshot.DepositAccountTypes), len(snapshot.Currencies), *out)
+}
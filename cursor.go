@@ -0,0 +1,162 @@
+package gotropipay
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cursor is a keyset position in the (createdAt, id) ordering of movements.
+// Its String form is an opaque token that can be stored and resumed later.
+// The zero Cursor starts at the beginning.
+type Cursor struct {
+	CreatedAt string `json:"c,omitempty"`
+	ID        string `json:"i,omitempty"`
+	// Skip counts rows sharing CreatedAt that are at or before ID, so the
+	// next request can fetch past them
+	Skip int `json:"s,omitempty"`
+}
+
+// IsZero reports whether the cursor points at the beginning
+func (c Cursor) IsZero() bool {
+	return c.CreatedAt == "" && c.ID == ""
+}
+
+// String encodes the cursor as an opaque URL-safe token
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	type plain Cursor
+	data, _ := json.Marshal(plain(c))
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (c Cursor) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (c *Cursor) UnmarshalText(text []byte) error {
+	parsed, err := ParseCursor(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// ParseCursor decodes a token produced by Cursor.String
+func ParseCursor(token string) (Cursor, error) {
+	type plain Cursor
+	var c plain
+	if token == "" {
+		return Cursor(c), nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return Cursor(c), nil
+}
+
+// MovementPage is one page of a keyset-paginated search
+type MovementPage struct {
+	Items   []Movement
+	Next    Cursor // Resume point; pass to the next call
+	HasMore bool
+}
+
+// SearchMovementsAfter returns up to limit movements strictly after the cursor
+// in (createdAt, id) order. Unlike offset paging, rows inserted during a long
+// scan neither shift nor duplicate the rows already returned.
+func (c *Client) SearchMovementsAfter(ctx context.Context, filter *MovementFilter, limit int, after Cursor) (*MovementPage, error) {
+	q := NewMovementQuery(DefaultMovementFields...).WithFilter(filter).Page(limit, 0).After(after)
+
+	res, err := c.QueryMovements(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &MovementPage{Next: res.Next, HasMore: res.HasMore}
+	for _, item := range res.Items {
+		page.Items = append(page.Items, item.Movement())
+	}
+	return page, nil
+}
+
+// movementKey is the comparable form of a (createdAt, id) pair
+type movementKey struct {
+	at time.Time
+	id string
+}
+
+func cursorKey(c Cursor) movementKey {
+	t, _ := time.Parse(time.RFC3339Nano, c.CreatedAt)
+	return movementKey{at: t, id: c.ID}
+}
+
+func recordKey(r MovementRecord) movementKey {
+	t, _ := time.Parse(time.RFC3339Nano, r.CreatedAt)
+	return movementKey{at: t, id: Movement{ID: r.ID}.IDString()}
+}
+
+// compare orders keys by time, then by ID (numerically when both are numbers)
+func (k movementKey) compare(o movementKey) int {
+	if c := k.at.Compare(o.at); c != 0 {
+		return c
+	}
+	a, errA := strconv.ParseInt(k.id, 10, 64)
+	b, errB := strconv.ParseInt(o.id, 10, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(k.id, o.id)
+}
+
+// applyKeyset drops rows at or before the cursor and computes the next cursor.
+// requested is the row count asked from the server (limit + skip).
+func applyKeyset(items []MovementRecord, after Cursor, limit, requested int) ([]MovementRecord, Cursor, bool) {
+	start := cursorKey(after)
+
+	var out []MovementRecord
+	for _, it := range items {
+		if !after.IsZero() && recordKey(it).compare(start) <= 0 {
+			continue
+		}
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, it)
+	}
+
+	if len(out) == 0 {
+		return out, after, false
+	}
+
+	last := out[len(out)-1]
+	lastKey := recordKey(last)
+	next := Cursor{CreatedAt: last.CreatedAt, ID: lastKey.id}
+	for _, it := range items {
+		k := recordKey(it)
+		if k.at.Equal(lastKey.at) && k.compare(lastKey) <= 0 {
+			next.Skip++
+		}
+	}
+
+	return out, next, len(items) >= requested
+}
@@ -0,0 +1,137 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// growingServer is a fake business endpoint that honours createdAtFrom,
+// orderBy and limit/offset, and inserts new rows on every request
+type growingServer struct {
+	mu   sync.Mutex
+	rows []map[string]interface{}
+	next int
+	base time.Time
+}
+
+func (s *growingServer) insert(at time.Time) {
+	s.next++
+	s.rows = append(s.rows, map[string]interface{}{
+		"id":        s.next,
+		"state":     "completed",
+		"createdAt": at.UTC().Format(time.RFC3339),
+		"amount":    map[string]interface{}{"value": 100, "currency": "EUR"},
+	})
+}
+
+func (s *growingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var req struct {
+		Variables struct {
+			Filter struct {
+				CreatedAtFrom string `json:"createdAtFrom"`
+			} `json:"filter"`
+			Pagination struct {
+				Limit  int `json:"limit"`
+				Offset int `json:"offset"`
+			} `json:"pagination"`
+			OrderBy []map[string]string `json:"orderBy"`
+		} `json:"variables"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	var from time.Time
+	if req.Variables.Filter.CreatedAtFrom != "" {
+		from, _ = time.Parse(time.RFC3339, req.Variables.Filter.CreatedAtFrom)
+	}
+
+	var rows []map[string]interface{}
+	for _, row := range s.rows {
+		at, _ := time.Parse(time.RFC3339, row["createdAt"].(string))
+		if !at.Before(from) {
+			rows = append(rows, row)
+		}
+	}
+
+	asc := len(req.Variables.OrderBy) > 0
+	sort.SliceStable(rows, func(i, j int) bool {
+		ai, aj := rows[i]["createdAt"].(string), rows[j]["createdAt"].(string)
+		if ai != aj {
+			return (ai < aj) == asc
+		}
+		return (rows[i]["id"].(int) < rows[j]["id"].(int)) == asc
+	})
+
+	total := len(rows)
+	off, lim := req.Variables.Pagination.Offset, req.Variables.Pagination.Limit
+	if off > len(rows) {
+		off = len(rows)
+	}
+	rows = rows[off:]
+	if lim > 0 && lim < len(rows) {
+		rows = rows[:lim]
+	}
+
+	writeJSON(w, map[string]interface{}{"data": map[string]interface{}{
+		"movements": map[string]interface{}{"items": rows, "totalCount": total},
+	}})
+
+	// New movements arrive while the client is scanning, some sharing the
+	// timestamp of the newest existing row
+	s.insert(s.base.Add(time.Duration(s.next) * time.Second))
+	s.insert(s.base.Add(time.Hour))
+}
+
+func TestSearchMovementsAfterIsConsistentUnderInserts(t *testing.T) {
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	srv := &growingServer{base: base}
+	for i := 0; i < 25; i++ {
+		// Groups of three rows share a timestamp to exercise the id tie-break
+		srv.insert(base.Add(time.Duration(i/3) * time.Second))
+	}
+	client := newFakeClient(t, srv)
+
+	seen := make(map[string]int)
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 100 {
+			t.Fatal("scan did not terminate")
+		}
+		after, err := gotropipay.ParseCursor(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, err := client.SearchMovementsAfter(context.Background(), nil, 4, after)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range page.Items {
+			seen[m.IDString()]++
+		}
+		token = page.Next.String()
+		if !page.HasMore {
+			break
+		}
+	}
+
+	for id, n := range seen {
+		if n != 1 {
+			t.Errorf("movement %s returned %d times", id, n)
+		}
+	}
+	for i := 1; i <= 25; i++ {
+		if seen[fmt.Sprint(i)] != 1 {
+			t.Errorf("pre-existing movement %d was skipped", i)
+		}
+	}
+}
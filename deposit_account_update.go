@@ -0,0 +1,129 @@
+package gotropipay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tropipay/gotropipay/validate"
+)
+
+// ErrDepositAccountConflict is returned by UpdateDepositAccountFields when the
+// beneficiary changed on the server since it was read
+var ErrDepositAccountConflict = errors.New("deposit account was modified concurrently")
+
+// FieldChange is one modified field of a beneficiary
+type FieldChange struct {
+	Field  string `json:"field"` // JSON field name
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// DepositAccountChange is an audit record of a beneficiary update
+type DepositAccountChange struct {
+	ID        int             `json:"id"`
+	Changes   []FieldChange   `json:"changes"`
+	Before    DepositAccount  `json:"before"`
+	After     *DepositAccount `json:"after,omitempty"` // Server state after the update; nil when nothing changed
+	UpdatedAt time.Time       `json:"updatedAt"`       // Local time the update was sent
+}
+
+// mutableDepositAccountFields lists the fields that can be changed in place.
+// Account number, country and type identify the destination and require a new
+// beneficiary.
+var mutableDepositAccountFields = []struct {
+	name string
+	get  func(*DepositAccount) string
+}{
+	{"alias", func(a *DepositAccount) string { return a.Alias }},
+	{"firstName", func(a *DepositAccount) string { return a.FirstName }},
+	{"lastName", func(a *DepositAccount) string { return a.LastName }},
+	{"documentNumber", func(a *DepositAccount) string { return a.DocumentNumber }},
+	{"address", func(a *DepositAccount) string { return a.Address }},
+	{"phone", func(a *DepositAccount) string { return a.Phone }},
+	{"email", func(a *DepositAccount) string { return a.Email }},
+	{"swift", func(a *DepositAccount) string { return a.Swift }},
+}
+
+// DiffDepositAccount lists the mutable fields that differ between two versions
+// of a beneficiary
+func DiffDepositAccount(before, after DepositAccount) []FieldChange {
+	var changes []FieldChange
+	for _, f := range mutableDepositAccountFields {
+		if b, a := f.get(&before), f.get(&after); b != a {
+			changes = append(changes, FieldChange{Field: f.name, Before: b, After: a})
+		}
+	}
+	return changes
+}
+
+// UpdateDepositAccountFields applies the differences between original (as
+// previously read from the API) and updated, sending only the changed fields.
+// original.UpdatedAt is sent along (as If-Match and in the body), so the
+// server rejects the update if the beneficiary changed since it was read;
+// ErrDepositAccountConflict is returned then. Changes to the ID or to
+// immutable fields (account number, country, type) are rejected.
+func (c *Client) UpdateDepositAccountFields(ctx context.Context, original, updated DepositAccount) (*DepositAccountChange, error) {
+	if original.ID != updated.ID {
+		return nil, fmt.Errorf("cannot apply changes of beneficiary %d to beneficiary %d", updated.ID, original.ID)
+	}
+	if original.AccountNumber != updated.AccountNumber ||
+		original.CountryDestinationID != updated.CountryDestinationID ||
+		original.Type != updated.Type {
+		return nil, errors.New("account number, country and type cannot be updated; create a new beneficiary")
+	}
+
+	change := &DepositAccountChange{
+		ID:      original.ID,
+		Changes: DiffDepositAccount(original, updated),
+		Before:  original,
+	}
+	if len(change.Changes) == 0 {
+		return change, nil
+	}
+	if updated.Swift != "" && updated.Swift != original.Swift {
+		if _, err := validate.BIC(updated.Swift); err != nil {
+			return nil, fmt.Errorf("invalid swift: %w", err)
+		}
+	}
+
+	// Fail early on a stale copy; the server enforces it again on the update
+	current, err := c.GetDepositAccount(ctx, original.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current.UpdatedAt != original.UpdatedAt {
+		return nil, fmt.Errorf("%w: updatedAt is %s, expected %s", ErrDepositAccountConflict, current.UpdatedAt, original.UpdatedAt)
+	}
+
+	payload := map[string]interface{}{"id": original.ID}
+	for _, fc := range change.Changes {
+		payload[fc.Field] = fc.After
+	}
+	var header http.Header
+	if original.UpdatedAt != "" {
+		payload["updatedAt"] = original.UpdatedAt
+		header = http.Header{"If-Match": {original.UpdatedAt}}
+	}
+
+	change.UpdatedAt = time.Now().UTC()
+	raw, err := c.send(ctx, "PUT", "/depositaccounts/", header, payload)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case raw.StatusCode == http.StatusConflict || raw.StatusCode == http.StatusPreconditionFailed:
+		return nil, fmt.Errorf("%w: rejected by the server (status: %d)", ErrDepositAccountConflict, raw.StatusCode)
+	case raw.StatusCode >= 400:
+		return nil, fmt.Errorf("API error: %s (status: %d) - %s", raw.URL, raw.StatusCode, string(raw.Body))
+	}
+	var resp DepositAccount
+	if err := json.Unmarshal(raw.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	change.After = &resp
+	return change, nil
+}
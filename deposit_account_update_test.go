@@ -0,0 +1,108 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestUpdateDepositAccountFieldsSendsOnlyChanges(t *testing.T) {
+	server := gotropipay.DepositAccount{ID: 7, AccountNumber: "ES9121000418450200051332", Email: "old@example.com", Phone: "+34600000000", UpdatedAt: "2026-10-01T10:00:00Z"}
+	var sent map[string]interface{}
+	var ifMatch string
+
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			writeJSON(w, server)
+		case "PUT":
+			ifMatch = r.Header.Get("If-Match")
+			_ = json.NewDecoder(r.Body).Decode(&sent)
+			server.Email = sent["email"].(string)
+			server.UpdatedAt = "2026-10-02T10:00:00Z"
+			writeJSON(w, server)
+		}
+	}))
+	ctx := context.Background()
+
+	original := server
+	updated := original
+	updated.Email = "new@example.com"
+
+	change, err := client.UpdateDepositAccountFields(ctx, original, updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 3 || sent["email"] != "new@example.com" || sent["id"] != float64(7) || sent["updatedAt"] != original.UpdatedAt {
+		t.Errorf("sent %v, want only id, email and updatedAt", sent)
+	}
+	if ifMatch != original.UpdatedAt {
+		t.Errorf("If-Match = %q, want %q", ifMatch, original.UpdatedAt)
+	}
+	if len(change.Changes) != 1 || change.Changes[0].Before != "old@example.com" || change.After.Email != "new@example.com" {
+		t.Errorf("change = %+v", change)
+	}
+
+	// original is now stale
+	updated.Phone = "+34611111111"
+	if _, err := client.UpdateDepositAccountFields(ctx, original, updated); !errors.Is(err, gotropipay.ErrDepositAccountConflict) {
+		t.Errorf("err = %v, want ErrDepositAccountConflict", err)
+	}
+}
+
+func TestUpdateDepositAccountFieldsServerConflict(t *testing.T) {
+	original := gotropipay.DepositAccount{ID: 7, AccountNumber: "ES9121000418450200051332", Alias: "old", UpdatedAt: "2026-10-01T10:00:00Z"}
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			writeJSON(w, original)
+		case "PUT":
+			// Someone else updated the beneficiary after our read
+			http.Error(w, `{"error":"stale"}`, http.StatusPreconditionFailed)
+		}
+	}))
+	ctx := context.Background()
+
+	updated := original
+	updated.Alias = "new"
+	if _, err := client.UpdateDepositAccountFields(ctx, original, updated); !errors.Is(err, gotropipay.ErrDepositAccountConflict) {
+		t.Errorf("err = %v, want ErrDepositAccountConflict", err)
+	}
+	updated.ID = 8
+	if _, err := client.UpdateDepositAccountFields(ctx, original, updated); err == nil {
+		t.Error("expected error for a different beneficiary ID")
+	}
+}
+
+func TestUpdateDepositAccountSendsEveryField(t *testing.T) {
+	var sent map[string]interface{}
+	var ifMatch string
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifMatch = r.Header.Get("If-Match")
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		writeJSON(w, gotropipay.DepositAccount{ID: 7})
+	}))
+	alias, email, phone := "home", "new@example.com", "+34611111111"
+	_, err := client.UpdateDepositAccount(context.Background(), gotropipay.UpdateDepositAccountRequest{
+		ID: 7, Alias: &alias, Email: &email, Phone: &phone, UpdatedAt: "2026-10-01T10:00:00Z",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 5 || sent["alias"] != alias || sent["email"] != email || sent["phone"] != phone || ifMatch != "2026-10-01T10:00:00Z" {
+		t.Errorf("sent %v with If-Match %q", sent, ifMatch)
+	}
+
+	// Fields left nil, the alias included, are not sent and so not cleared
+	sent = nil
+	if _, err := client.UpdateDepositAccount(context.Background(), gotropipay.UpdateDepositAccountRequest{ID: 7, Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sent["alias"]; ok || len(sent) != 2 {
+		t.Errorf("sent %v, want only id and email", sent)
+	}
+}
@@ -3,8 +3,11 @@ package gotropipay
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
+
+	"github.com/tropipay/gotropipay/validate"
 )
 
 // CountryDestination represents destination country details
@@ -14,6 +17,8 @@ type CountryDestination struct {
 	SepaZone    bool   `json:"sepaZone"`
 	Slug        string `json:"slug"`
 	CallingCode int    `json:"callingCode"`
+	Code        string `json:"code,omitempty"`     // ISO 3166-1 alpha-2
+	Currency    string `json:"currency,omitempty"` // Default payout currency
 }
 
 // AllowedAccount represents an account allowed for the beneficiary
@@ -62,10 +67,21 @@ type CreateDepositAccountRequest struct {
 	Swift                string `json:"swift,omitempty"`
 }
 
-// UpdateDepositAccountRequest represents payload to update a beneficiary
+// UpdateDepositAccountRequest represents payload to update a beneficiary.
+// Nil fields are left unchanged.
 type UpdateDepositAccountRequest struct {
-	ID    int    `json:"id"`
-	Alias string `json:"alias"`
+	ID             int     `json:"id"`
+	Alias          *string `json:"alias,omitempty"`
+	FirstName      *string `json:"firstName,omitempty"`
+	LastName       *string `json:"lastName,omitempty"`
+	DocumentNumber *string `json:"documentNumber,omitempty"`
+	Address        *string `json:"address,omitempty"`
+	Phone          *string `json:"phone,omitempty"`
+	Email          *string `json:"email,omitempty"`
+	Swift          *string `json:"swift,omitempty"`
+	// UpdatedAt, if set, is sent as If-Match so the server rejects the update
+	// when the beneficiary changed since it was read
+	UpdatedAt string `json:"updatedAt,omitempty"`
 }
 
 // DeleteDepositAccountRequest represents payload to delete a beneficiary
@@ -95,8 +111,29 @@ type listDepositAccountsResponse struct {
 	Items []DepositAccount `json:"items"`
 }
 
-// CreateDepositAccount creates a new beneficiary record
+// Validate runs the offline checks that need no reference data: IBAN-shaped
+// account numbers must pass the checksum and Swift must be a well-formed BIC.
+// Other account formats are left to ValidateAccountNumber.
+func (r CreateDepositAccountRequest) Validate() error {
+	if validate.LooksLikeIBAN(r.AccountNumber) {
+		if _, err := validate.IBAN(r.AccountNumber); err != nil {
+			return fmt.Errorf("invalid account number: %w", err)
+		}
+	}
+	if r.Swift != "" {
+		if _, err := validate.BIC(r.Swift); err != nil {
+			return fmt.Errorf("invalid swift: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateDepositAccount creates a new beneficiary record. Obviously malformed
+// requests are rejected locally (see CreateDepositAccountRequest.Validate).
 func (c *Client) CreateDepositAccount(ctx context.Context, req CreateDepositAccountRequest) (*DepositAccount, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
 	var resp DepositAccount
 	err := c.Request(ctx, "POST", "/depositaccounts/", req, &resp)
 	if err != nil {
@@ -142,19 +179,32 @@ func (c *Client) GetDepositAccount(ctx context.Context, id int) (*DepositAccount
 	return &resp, nil
 }
 
-// UpdateDepositAccount updates a beneficiary alias
+// UpdateDepositAccount updates the non-nil fields of a beneficiary. Use
+// UpdateDepositAccountFields to send only what changed between two copies.
 func (c *Client) UpdateDepositAccount(ctx context.Context, req UpdateDepositAccountRequest) (*DepositAccount, error) {
+	var header http.Header
+	if req.UpdatedAt != "" {
+		header = http.Header{"If-Match": {req.UpdatedAt}}
+	}
 	var resp DepositAccount
-	err := c.Request(ctx, "PUT", "/depositaccounts/", req, &resp)
+	err := c.requestWithHeaders(ctx, "PUT", "/depositaccounts/", header, req, &resp)
 	if err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-// DeleteDepositAccount deletes a beneficiary
+// DeleteDepositAccount deletes a beneficiary. An empty securityCode is
+// obtained from the client's SecurityCodeProvider.
 func (c *Client) DeleteDepositAccount(ctx context.Context, id int, securityCode string) error {
 	path := fmt.Sprintf("/depositaccounts/%d", id)
+	securityCode, err := c.securityCode(ctx, securityCode, SecurityCodeRequest{
+		Operation: "deleteDepositAccount",
+		Resource:  strconv.Itoa(id),
+	})
+	if err != nil {
+		return err
+	}
 	req := DeleteDepositAccountRequest{SecurityCode: securityCode}
 	return c.Request(ctx, "DELETE", path, req, nil)
 }
@@ -0,0 +1,136 @@
+package export
+
+import (
+	"io"
+	"iter"
+	"strings"
+
+	"github.com/tropipay/gotropipay"
+)
+
+const (
+	camtDateTime = "2006-01-02T15:04:05Z"
+	camtDate     = "2006-01-02"
+)
+
+// WriteCAMT053 writes movements as an ISO 20022 camt.053.001.02 bank-to-customer statement
+func WriteCAMT053(w io.Writer, stmt Statement, movements iter.Seq2[gotropipay.Movement, error]) error {
+	sp, err := newSpool(movements, stmt.Currency)
+	if err != nil {
+		return err
+	}
+	defer sp.Close()
+	stmt = sp.resolve(stmt)
+
+	ccy := xmlText(stmt.Currency)
+
+	ew := newErrWriter(w)
+	ew.printf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	ew.printf("<Document xmlns=\"urn:iso:std:iso:20022:tech:xsd:camt.053.001.02\">\n")
+	ew.printf("  <BkToCstmrStmt>\n")
+	ew.printf("    <GrpHdr>\n")
+	ew.printf("      <MsgId>%s</MsgId>\n", xmlText(stmt.ID))
+	ew.printf("      <CreDtTm>%s</CreDtTm>\n", stmt.CreatedAt.UTC().Format(camtDateTime))
+	ew.printf("    </GrpHdr>\n")
+	ew.printf("    <Stmt>\n")
+	ew.printf("      <Id>%s</Id>\n", xmlText(stmt.ID))
+	ew.printf("      <CreDtTm>%s</CreDtTm>\n", stmt.CreatedAt.UTC().Format(camtDateTime))
+	ew.printf("      <FrToDt><FrDtTm>%s</FrDtTm><ToDtTm>%s</ToDtTm></FrToDt>\n",
+		stmt.From.UTC().Format(camtDateTime), stmt.To.UTC().Format(camtDateTime))
+	ew.printf("      <Acct>\n")
+	if isIBAN(stmt.AccountID) {
+		ew.printf("        <Id><IBAN>%s</IBAN></Id>\n", xmlText(stmt.AccountID))
+	} else {
+		ew.printf("        <Id><Othr><Id>%s</Id></Othr></Id>\n", xmlText(stmt.AccountID))
+	}
+	ew.printf("        <Ccy>%s</Ccy>\n", ccy)
+	ew.printf("      </Acct>\n")
+	writeCAMTBalance(ew, "OPBD", sp.sum.opening, ccy, stmt.From.UTC().Format(camtDate))
+	writeCAMTBalance(ew, "CLBD", sp.sum.closing, ccy, stmt.To.UTC().Format(camtDate))
+	ew.printf("      <TxsSummry>\n")
+	ew.printf("        <TtlNtries><NbOfNtries>%d</NbOfNtries></TtlNtries>\n", sp.sum.count)
+	ew.printf("        <TtlCdtNtries><Sum>%s</Sum></TtlCdtNtries>\n", formatAmount(sp.sum.credits, "."))
+	ew.printf("        <TtlDbtNtries><Sum>%s</Sum></TtlDbtNtries>\n", formatAmount(sp.sum.debits, "."))
+	ew.printf("      </TxsSummry>\n")
+
+	err = sp.each(func(m gotropipay.Movement) error {
+		amt := m.SignedAmount()
+		status := "BOOK"
+		if !strings.EqualFold(m.State, string(gotropipay.MovementStateCompleted)) {
+			status = "PDNG"
+		}
+		date := m.BookingTime().UTC().Format(camtDate)
+
+		ew.printf("      <Ntry>\n")
+		ew.printf("        <NtryRef>%s</NtryRef>\n", xmlText(m.IDString()))
+		ew.printf("        <Amt Ccy=\"%s\">%s</Amt>\n", xmlText(m.Currency), formatAmount(abs(amt), "."))
+		ew.printf("        <CdtDbtInd>%s</CdtDbtInd>\n", creditDebit(amt))
+		ew.printf("        <Sts>%s</Sts>\n", status)
+		ew.printf("        <BookgDt><Dt>%s</Dt></BookgDt>\n", date)
+		ew.printf("        <ValDt><Dt>%s</Dt></ValDt>\n", date)
+		ew.printf("        <BkTxCd><Prtry><Cd>TROPIPAY</Cd></Prtry></BkTxCd>\n")
+		ew.printf("        <NtryDtls>\n          <TxDtls>\n")
+		ew.printf("            <Refs><EndToEndId>%s</EndToEndId></Refs>\n", xmlText(orNotProvided(m.Reference)))
+		if name := counterparty(m, amt); name != "" {
+			role := "Dbtr"
+			if amt < 0 {
+				role = "Cdtr"
+			}
+			ew.printf("            <RltdPties><%s><Nm>%s</Nm></%s></RltdPties>\n", role, xmlText(name), role)
+		}
+		if m.Reference != "" {
+			ew.printf("            <RmtInf><Ustrd>%s</Ustrd></RmtInf>\n", xmlText(m.Reference))
+		}
+		ew.printf("          </TxDtls>\n        </NtryDtls>\n")
+		ew.printf("      </Ntry>\n")
+		return ew.err
+	})
+	if err != nil {
+		return err
+	}
+
+	ew.printf("    </Stmt>\n")
+	ew.printf("  </BkToCstmrStmt>\n")
+	ew.printf("</Document>\n")
+	return ew.flush()
+}
+
+func writeCAMTBalance(ew *errWriter, code string, amount int64, ccy, date string) {
+	ew.printf("      <Bal>\n")
+	ew.printf("        <Tp><CdOrPrtry><Cd>%s</Cd></CdOrPrtry></Tp>\n", code)
+	ew.printf("        <Amt Ccy=\"%s\">%s</Amt>\n", ccy, formatAmount(abs(amount), "."))
+	ew.printf("        <CdtDbtInd>%s</CdtDbtInd>\n", creditDebit(amount))
+	ew.printf("        <Dt><Dt>%s</Dt></Dt>\n", date)
+	ew.printf("      </Bal>\n")
+}
+
+func creditDebit(amount int64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+func orNotProvided(s string) string {
+	if s == "" {
+		return "NOTPROVIDED"
+	}
+	return s
+}
+
+// isIBAN is a cheap shape check used to pick the account identification scheme
+func isIBAN(s string) bool {
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case i < 2 && r >= 'A' && r <= 'Z':
+		case i >= 2 && i < 4 && r >= '0' && r <= '9':
+		case i >= 4 && (r >= '0' && r <= '9' || r >= 'A' && r <= 'Z'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"iter"
+	"strconv"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// Column is a single CSV column
+type Column struct {
+	Header string
+	Value  func(gotropipay.Movement) string
+}
+
+// Predefined columns
+var (
+	ColumnID            = Column{"id", func(m gotropipay.Movement) string { return m.IDString() }}
+	ColumnCreatedAt     = Column{"created_at", func(m gotropipay.Movement) string { return m.CreatedAt }}
+	ColumnCompletedAt   = Column{"completed_at", func(m gotropipay.Movement) string { return m.CompletedAt }}
+	ColumnState         = Column{"state", func(m gotropipay.Movement) string { return m.State }}
+	ColumnReference     = Column{"reference", func(m gotropipay.Movement) string { return m.Reference }}
+	ColumnAmount        = Column{"amount", func(m gotropipay.Movement) string { return formatAmount(m.Amount, ".") }}
+	ColumnAmountCents   = Column{"amount_cents", func(m gotropipay.Movement) string { return strconv.FormatInt(m.Amount, 10) }}
+	ColumnCurrency      = Column{"currency", func(m gotropipay.Movement) string { return m.Currency }}
+	ColumnBalanceBefore = Column{"balance_before", func(m gotropipay.Movement) string { return formatAmount(m.BalanceBefore, ".") }}
+	ColumnBalanceAfter  = Column{"balance_after", func(m gotropipay.Movement) string { return formatAmount(m.BalanceAfter, ".") }}
+	ColumnSender        = Column{"sender", gotropipay.Movement.SenderName}
+	ColumnRecipient     = Column{"recipient", gotropipay.Movement.RecipientName}
+)
+
+// DefaultColumns is used by WriteCSV when no columns are given
+var DefaultColumns = []Column{
+	ColumnID, ColumnCreatedAt, ColumnCompletedAt, ColumnState, ColumnReference,
+	ColumnAmount, ColumnCurrency, ColumnBalanceBefore, ColumnBalanceAfter,
+}
+
+// WriteCSV streams movements as CSV rows with the given columns
+func WriteCSV(w io.Writer, movements iter.Seq2[gotropipay.Movement, error], columns []Column) error {
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+
+	cw := csv.NewWriter(w)
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		row[i] = c.Header
+	}
+	if err := cw.Write(row); err != nil {
+		return err
+	}
+
+	for m, err := range movements {
+		if err != nil {
+			return err
+		}
+		for i, c := range columns {
+			row[i] = c.Value(m)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
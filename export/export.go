@@ -0,0 +1,215 @@
+// Package export streams movements into accounting and bank statement formats.
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// Statement describes the account and period a statement file covers
+type Statement struct {
+	ID        string    // Statement / message identifier
+	AccountID string    // IBAN or Tropipay account identifier
+	Currency  string    // Defaults to the currency of the first movement; others are rejected
+	From      time.Time // Defaults to the earliest movement date
+	To        time.Time // Defaults to the latest movement date
+	CreatedAt time.Time // Defaults to time.Now()
+}
+
+// summary holds the statement figures derived from the movements
+type summary struct {
+	count   int
+	from    time.Time
+	to      time.Time
+	opening int64
+	closing int64
+	credits int64
+	debits  int64
+}
+
+// spool buffers movements on disk so statement headers can carry totals
+// without holding every movement in memory. Failed and cancelled movements
+// moved no money and are left out.
+type spool struct {
+	file *os.File
+	sum  summary
+	ccy  string
+}
+
+// spooled is one movement on disk. The ID is kept as raw JSON so that large
+// numeric IDs survive the round trip exactly.
+type spooled struct {
+	ID       json.RawMessage     `json:"id"`
+	Movement gotropipay.Movement `json:"movement"`
+}
+
+// newSpool buffers the statement's movements. Every movement must be in
+// currency; an empty currency is taken from the first movement.
+func newSpool(movements iter.Seq2[gotropipay.Movement, error], currency string) (*spool, error) {
+	f, err := os.CreateTemp("", "gotropipay-export-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	s := &spool{file: f, ccy: currency}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for m, err := range movements {
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		switch gotropipay.MovementState(strings.ToLower(m.State)) {
+		case gotropipay.MovementStateFailed, gotropipay.MovementStateCancelled:
+			continue
+		}
+		if s.ccy == "" {
+			s.ccy = m.Currency
+		}
+		if !strings.EqualFold(m.Currency, s.ccy) {
+			s.Close()
+			return nil, fmt.Errorf("movement %s is in %s, statement is in %s", m.IDString(), m.Currency, s.ccy)
+		}
+		id, err := json.Marshal(m.ID)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		if err := enc.Encode(spooled{ID: id, Movement: m}); err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.add(m)
+	}
+	if err := w.Flush(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *spool) add(m gotropipay.Movement) {
+	t := m.BookingTime().UTC()
+	if s.sum.count == 0 || t.Before(s.sum.from) {
+		s.sum.from = t
+		s.sum.opening = m.BalanceBefore
+	}
+	if s.sum.count == 0 || !t.Before(s.sum.to) {
+		s.sum.to = t
+		s.sum.closing = m.BalanceAfter
+	}
+	amt := m.SignedAmount()
+	if amt >= 0 {
+		s.sum.credits += amt
+	} else {
+		s.sum.debits -= amt
+	}
+	s.sum.count++
+}
+
+// each replays the spooled movements in their original order
+func (s *spool) each(fn func(gotropipay.Movement) error) error {
+	dec := json.NewDecoder(bufio.NewReader(s.file))
+	for dec.More() {
+		var rec spooled
+		if err := dec.Decode(&rec); err != nil {
+			return err
+		}
+		idDec := json.NewDecoder(bytes.NewReader(rec.ID))
+		idDec.UseNumber()
+		if err := idDec.Decode(&rec.Movement.ID); err != nil {
+			return err
+		}
+		if err := fn(rec.Movement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *spool) Close() {
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
+
+// resolve fills the statement defaults from the spooled movements
+func (s *spool) resolve(stmt Statement) Statement {
+	if stmt.Currency == "" {
+		stmt.Currency = s.ccy
+	}
+	if stmt.From.IsZero() {
+		stmt.From = s.sum.from
+	}
+	if stmt.To.IsZero() {
+		stmt.To = s.sum.to
+	}
+	if stmt.CreatedAt.IsZero() {
+		stmt.CreatedAt = time.Now()
+	}
+	if stmt.ID == "" {
+		stmt.ID = stmt.CreatedAt.UTC().Format("20060102150405")
+	}
+	return stmt
+}
+
+// formatAmount renders cents as a decimal with the given separator
+func formatAmount(cents int64, sep string) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d%s%02d", sign, cents/100, sep, cents%100)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// errWriter remembers the first write error so templates can be written linearly
+type errWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func newErrWriter(w io.Writer) *errWriter {
+	return &errWriter{w: bufio.NewWriter(w)}
+}
+
+func (e *errWriter) printf(format string, args ...interface{}) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = fmt.Fprintf(e.w, format, args...)
+}
+
+func (e *errWriter) flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// xmlText escapes s for use as XML character data
+func xmlText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+	"github.com/tropipay/gotropipay/export"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func fixture() iter.Seq2[gotropipay.Movement, error] {
+	movements := []gotropipay.Movement{
+		{
+			ID: float64(102), Amount: 2500, Currency: "EUR", State: "completed", Reference: "PAYOUT-7",
+			CreatedAt: "2026-10-02T09:00:00Z", CompletedAt: "2026-10-02T09:05:00Z",
+			BalanceBefore: 11500, BalanceAfter: 9000,
+			Recipient: &gotropipay.MovementParty{Name: "Ana López"},
+		},
+		{
+			ID: float64(101), Amount: 1500, Currency: "EUR", State: "completed", Reference: "ORDER-1234",
+			CreatedAt: "2026-10-01T10:00:00Z", CompletedAt: "2026-10-01T10:01:00Z",
+			BalanceBefore: 10000, BalanceAfter: 11500,
+			Sender: &gotropipay.MovementParty{Name: "John & Co"},
+		},
+	}
+	return func(yield func(gotropipay.Movement, error) bool) {
+		for _, m := range movements {
+			if !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
+
+var stmt = export.Statement{
+	ID:        "STMT-2026-10",
+	AccountID: "ES9121000418450200051332",
+	CreatedAt: time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC),
+}
+
+func TestGolden(t *testing.T) {
+	cases := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"movements.csv", func(w io.Writer) error { return export.WriteCSV(w, fixture(), nil) }},
+		{"movements.ofx", func(w io.Writer) error { return export.WriteOFX(w, stmt, fixture()) }},
+		{"movements.camt053.xml", func(w io.Writer) error { return export.WriteCAMT053(w, stmt, fixture()) }},
+		{"movements.mt940", func(w io.Writer) error { return export.WriteMT940(w, stmt, fixture()) }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.write(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tc.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output mismatch for %s (run with -update to accept)\ngot:\n%s", tc.name, buf.String())
+			}
+		})
+	}
+}
+
+func seq(movements ...gotropipay.Movement) iter.Seq2[gotropipay.Movement, error] {
+	return func(yield func(gotropipay.Movement, error) bool) {
+		for _, m := range movements {
+			if !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestStatementsSkipFailedMovements(t *testing.T) {
+	var buf bytes.Buffer
+	err := export.WriteMT940(&buf, stmt, seq(
+		gotropipay.Movement{ID: 1, Amount: 1000, Currency: "EUR", State: "completed", CreatedAt: "2026-10-01T10:00:00Z", BalanceBefore: 0, BalanceAfter: 1000},
+		gotropipay.Movement{ID: 2, Amount: 5000, Currency: "EUR", State: "Failed", CreatedAt: "2026-10-02T10:00:00Z", BalanceBefore: 1000, BalanceAfter: 6000},
+		gotropipay.Movement{ID: 3, Amount: 700, Currency: "EUR", State: "cancelled", CreatedAt: "2026-10-02T11:00:00Z"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Count(out, ":61:") != 1 || !strings.Contains(out, ":62F:C261001EUR10,00") {
+		t.Errorf("failed and cancelled movements were booked:\n%s", out)
+	}
+}
+
+func TestStatementsRejectOtherCurrencies(t *testing.T) {
+	movements := seq(
+		gotropipay.Movement{ID: 1, Amount: 1000, Currency: "EUR", State: "completed", CreatedAt: "2026-10-01T10:00:00Z"},
+		gotropipay.Movement{ID: 2, Amount: 1000, Currency: "USD", State: "completed", CreatedAt: "2026-10-01T11:00:00Z"},
+	)
+	if err := export.WriteCAMT053(io.Discard, stmt, movements); err == nil || !strings.Contains(err.Error(), "USD") {
+		t.Errorf("err = %v, want a currency mismatch", err)
+	}
+}
+
+func TestMT940KeepsIDsAndWrapsDetails(t *testing.T) {
+	var buf bytes.Buffer
+	err := export.WriteMT940(&buf, stmt, seq(gotropipay.Movement{
+		ID: json.Number("9007199254740993"), Amount: 1000, Currency: "EUR", State: "completed",
+		CreatedAt: "2026-10-01T10:00:00Z", Reference: strings.Repeat("R", 100),
+		Sender: &gotropipay.MovementParty{Name: "Ana"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "//9007199254740993\r\n") {
+		t.Errorf("large ID not kept exactly:\n%s", out)
+	}
+	info := out[strings.Index(out, ":86:")+4 : strings.Index(out, ":62F:")]
+	lines := strings.Split(strings.TrimSuffix(info, "\r\n"), "\r\n")
+	if len(lines) != 2 || len(lines[0]) != 65 || lines[0]+lines[1] != "Ana "+strings.Repeat("R", 100) {
+		t.Errorf(":86: lines = %q", lines)
+	}
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"io"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// WriteMT940 writes movements as a SWIFT MT940 customer statement (text block only)
+func WriteMT940(w io.Writer, stmt Statement, movements iter.Seq2[gotropipay.Movement, error]) error {
+	sp, err := newSpool(movements, stmt.Currency)
+	if err != nil {
+		return err
+	}
+	defer sp.Close()
+	stmt = sp.resolve(stmt)
+
+	ew := newErrWriter(w)
+	ew.printf(":20:%s\r\n", swiftText(stmt.ID, 16))
+	ew.printf(":25:%s\r\n", swiftText(stmt.AccountID, 35))
+	ew.printf(":28C:1\r\n")
+	ew.printf(":60F:%s\r\n", mt940Balance(sp.sum.opening, stmt.From, stmt.Currency))
+
+	err = sp.each(func(m gotropipay.Movement) error {
+		amt := m.SignedAmount()
+		t := m.BookingTime().UTC()
+		mark := "C"
+		if amt < 0 {
+			mark = "D"
+		}
+		ref := swiftText(m.Reference, 16)
+		if ref == "" {
+			ref = "NONREF"
+		}
+		ew.printf(":61:%s%s%s%sNTRF%s//%s\r\n",
+			t.Format("060102"), t.Format("0102"), mark, formatAmount(abs(amt), ","), ref, swiftText(m.IDString(), 16))
+
+		info := m.Reference
+		if name := counterparty(m, amt); name != "" {
+			info = strings.TrimSpace(name + " " + info)
+		}
+		if info != "" {
+			ew.printf(":86:%s\r\n", strings.Join(mt940Lines(swiftText(info, 6*65), 65), "\r\n"))
+		}
+		return ew.err
+	})
+	if err != nil {
+		return err
+	}
+
+	ew.printf(":62F:%s\r\n", mt940Balance(sp.sum.closing, stmt.To, stmt.Currency))
+	ew.printf("-\r\n")
+	return ew.flush()
+}
+
+func mt940Balance(amount int64, date time.Time, ccy string) string {
+	mark := "C"
+	if amount < 0 {
+		mark = "D"
+	}
+	return mark + date.UTC().Format("060102") + ccy + formatAmount(abs(amount), ",")
+}
+
+// mt940Lines splits s into lines of at most n characters
+func mt940Lines(s string, n int) []string {
+	var lines []string
+	for r := []rune(s); len(r) > 0; {
+		k := min(n, len(r))
+		lines = append(lines, string(r[:k]))
+		r = r[k:]
+	}
+	return lines
+}
+
+// swiftText restricts s to the SWIFT X character set and truncates it to n characters
+func swiftText(s string, n int) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case strings.ContainsRune("/-?:().,'+ ", r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('.')
+		}
+	}
+	return truncate(b.String(), n)
+}
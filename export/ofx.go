@@ -0,0 +1,86 @@
+package export
+
+import (
+	"io"
+	"iter"
+
+	"github.com/tropipay/gotropipay"
+)
+
+const ofxTime = "20060102150405"
+
+// WriteOFX writes movements as an OFX 2.2 bank statement
+func WriteOFX(w io.Writer, stmt Statement, movements iter.Seq2[gotropipay.Movement, error]) error {
+	sp, err := newSpool(movements, stmt.Currency)
+	if err != nil {
+		return err
+	}
+	defer sp.Close()
+	stmt = sp.resolve(stmt)
+
+	ew := newErrWriter(w)
+	ew.printf("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"no\"?>\n")
+	ew.printf("<?OFX OFXHEADER=\"200\" VERSION=\"220\" SECURITY=\"NONE\" OLDFILEUID=\"NONE\" NEWFILEUID=\"NONE\"?>\n")
+	ew.printf("<OFX>\n")
+	ew.printf("  <SIGNONMSGSRSV1>\n    <SONRS>\n")
+	ew.printf("      <STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	ew.printf("      <DTSERVER>%s</DTSERVER>\n", stmt.CreatedAt.UTC().Format(ofxTime))
+	ew.printf("      <LANGUAGE>ENG</LANGUAGE>\n")
+	ew.printf("    </SONRS>\n  </SIGNONMSGSRSV1>\n")
+	ew.printf("  <BANKMSGSRSV1>\n    <STMTTRNRS>\n")
+	ew.printf("      <TRNUID>%s</TRNUID>\n", xmlText(stmt.ID))
+	ew.printf("      <STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	ew.printf("      <STMTRS>\n")
+	ew.printf("        <CURDEF>%s</CURDEF>\n", xmlText(stmt.Currency))
+	ew.printf("        <BANKACCTFROM><BANKID>TROPIPAY</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>\n", xmlText(stmt.AccountID))
+	ew.printf("        <BANKTRANLIST>\n")
+	ew.printf("          <DTSTART>%s</DTSTART>\n", stmt.From.UTC().Format(ofxTime))
+	ew.printf("          <DTEND>%s</DTEND>\n", stmt.To.UTC().Format(ofxTime))
+
+	err = sp.each(func(m gotropipay.Movement) error {
+		amt := m.SignedAmount()
+		trnType := "CREDIT"
+		if amt < 0 {
+			trnType = "DEBIT"
+		}
+		ew.printf("          <STMTTRN>\n")
+		ew.printf("            <TRNTYPE>%s</TRNTYPE>\n", trnType)
+		ew.printf("            <DTPOSTED>%s</DTPOSTED>\n", m.BookingTime().UTC().Format(ofxTime))
+		ew.printf("            <TRNAMT>%s</TRNAMT>\n", formatAmount(amt, "."))
+		ew.printf("            <FITID>%s</FITID>\n", xmlText(m.IDString()))
+		if name := counterparty(m, amt); name != "" {
+			ew.printf("            <NAME>%s</NAME>\n", xmlText(truncate(name, 32)))
+		}
+		if m.Reference != "" {
+			ew.printf("            <MEMO>%s</MEMO>\n", xmlText(m.Reference))
+		}
+		ew.printf("          </STMTTRN>\n")
+		return ew.err
+	})
+	if err != nil {
+		return err
+	}
+
+	ew.printf("        </BANKTRANLIST>\n")
+	ew.printf("        <LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>\n",
+		formatAmount(sp.sum.closing, "."), stmt.To.UTC().Format(ofxTime))
+	ew.printf("      </STMTRS>\n    </STMTTRNRS>\n  </BANKMSGSRSV1>\n</OFX>\n")
+	return ew.flush()
+}
+
+// counterparty returns the other side of the movement: the sender for
+// credits and the recipient for debits
+func counterparty(m gotropipay.Movement, amt int64) string {
+	if amt < 0 {
+		return m.RecipientName()
+	}
+	return m.SenderName()
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
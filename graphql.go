@@ -0,0 +1,248 @@
+package gotropipay
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultGraphQLPath is the business GraphQL endpoint
+const DefaultGraphQLPath = "/movements/business"
+
+// GraphQLOperation is a single GraphQL operation
+type GraphQLOperation struct {
+	OperationName string                 // Selects the operation in a multi-operation document
+	Query         string                 // GraphQL document
+	Variables     map[string]interface{} // Operation variables
+
+	// Persisted sends the query's sha256 hash first and only falls back to the
+	// full document when the server does not know it (automatic persisted queries)
+	Persisted bool
+}
+
+// graphQLRequest is the wire format of a GraphQL request
+type graphQLRequest struct {
+	Query         string                 `json:"query,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// graphQLResponse is the wire format of a GraphQL response
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
+
+// GraphQLLocation points at the part of the document an error refers to
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError is a single entry of a GraphQL "errors" array
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Code returns extensions.code, if any
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// PathString renders the error path as "movements.items.3.amount"
+func (e GraphQLError) PathString() string {
+	parts := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		switch v := p.(type) {
+		case float64:
+			parts[i] = fmt.Sprintf("%d", int(v))
+		default:
+			parts[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func (e GraphQLError) Error() string {
+	msg := e.Message
+	if p := e.PathString(); p != "" {
+		msg += " (path: " + p + ")"
+	}
+	if code := e.Code(); code != "" {
+		msg += " [" + code + "]"
+	}
+	return msg
+}
+
+// GraphQLErrors is returned when a GraphQL response carries errors.
+// Any partial data has still been decoded into the output value.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "graphql error"
+	case 1:
+		return "graphql error: " + e[0].Error()
+	default:
+		return fmt.Sprintf("graphql error: %s (and %d more)", e[0].Error(), len(e)-1)
+	}
+}
+
+// HasCode reports whether any error carries the given extensions.code
+func (e GraphQLErrors) HasCode(code string) bool {
+	for _, err := range e {
+		if err.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// GraphQL executes a query against the business GraphQL endpoint and decodes
+// "data" into out. If the response contains errors, out still receives any
+// partial data and the returned error is a GraphQLErrors.
+func (c *Client) GraphQL(ctx context.Context, query string, vars map[string]interface{}, out interface{}) error {
+	return c.ExecuteGraphQL(ctx, GraphQLOperation{Query: query, Variables: vars}, out)
+}
+
+// ExecuteGraphQL executes a named and/or persisted operation. See GraphQL.
+func (c *Client) ExecuteGraphQL(ctx context.Context, op GraphQLOperation, out interface{}) error {
+	req := op.wireRequest(!op.Persisted)
+
+	resp, err := c.postGraphQL(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if op.Persisted && persistedQueryNotFound(resp.Errors) {
+		resp, err = c.postGraphQL(ctx, op.wireRequest(true))
+		if err != nil {
+			return err
+		}
+	}
+
+	return resp.decode(out)
+}
+
+// GraphQLBatch sends several operations in one HTTP call. If the server
+// rejects the batch itself (a 400 with a JSON object instead of an array),
+// nothing was executed, so the operations are sent one by one and batching
+// is not attempted again by this client. Any other failure is returned as is:
+// the batch may have run, and mutations must not be replayed. The returned
+// slice holds one error per operation (nil on success); the second return
+// value is a transport or API error.
+func (c *Client) GraphQLBatch(ctx context.Context, ops []GraphQLOperation, outs []interface{}) ([]error, error) {
+	if len(outs) != len(ops) {
+		return nil, fmt.Errorf("graphql batch: %d operations but %d outputs", len(ops), len(outs))
+	}
+
+	errs := make([]error, len(ops))
+	if !c.gqlBatchUnsupported.Load() {
+		reqs := make([]graphQLRequest, len(ops))
+		for i, op := range ops {
+			reqs[i] = op.wireRequest(true)
+		}
+
+		raw, err := c.send(ctx, "POST", c.graphQLPath, nil, reqs)
+		if err != nil {
+			return nil, err
+		}
+
+		body := bytes.TrimSpace(raw.Body)
+		switch {
+		case raw.StatusCode == http.StatusBadRequest && len(body) > 0 && body[0] == '{' && json.Valid(body):
+			c.gqlBatchUnsupported.Store(true)
+		case raw.StatusCode >= 400:
+			return nil, fmt.Errorf("API error: %s (status: %d) - %s", raw.URL, raw.StatusCode, string(raw.Body))
+		default:
+			var resps []graphQLResponse
+			if err := json.Unmarshal(body, &resps); err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+			if len(resps) != len(ops) {
+				return nil, fmt.Errorf("graphql batch: %d operations but %d responses", len(ops), len(resps))
+			}
+			for i := range resps {
+				errs[i] = resps[i].decode(outs[i])
+			}
+			return errs, nil
+		}
+	}
+
+	for i, op := range ops {
+		errs[i] = c.ExecuteGraphQL(ctx, op, outs[i])
+		if ctx.Err() != nil {
+			return errs, ctx.Err()
+		}
+	}
+	return errs, nil
+}
+
+func (c *Client) postGraphQL(ctx context.Context, req graphQLRequest) (*graphQLResponse, error) {
+	raw, err := c.send(ctx, "POST", c.graphQLPath, nil, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp graphQLResponse
+	if err := json.Unmarshal(raw.Body, &resp); err != nil || (raw.StatusCode >= 400 && len(resp.Errors) == 0) {
+		if raw.StatusCode >= 400 {
+			return nil, fmt.Errorf("API error: %s (status: %d) - %s", raw.URL, raw.StatusCode, string(raw.Body))
+		}
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &resp, nil
+}
+
+// decode unmarshals the data into out and returns the errors, if any
+func (r *graphQLResponse) decode(out interface{}) error {
+	if out != nil && len(r.Data) > 0 && string(r.Data) != "null" {
+		if err := json.Unmarshal(r.Data, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	if len(r.Errors) > 0 {
+		return r.Errors
+	}
+	return nil
+}
+
+func (op GraphQLOperation) wireRequest(includeQuery bool) graphQLRequest {
+	req := graphQLRequest{
+		OperationName: op.OperationName,
+		Variables:     op.Variables,
+	}
+	if includeQuery {
+		req.Query = op.Query
+	}
+	if op.Persisted {
+		sum := sha256.Sum256([]byte(op.Query))
+		req.Extensions = map[string]interface{}{
+			"persistedQuery": map[string]interface{}{
+				"version":    1,
+				"sha256Hash": hex.EncodeToString(sum[:]),
+			},
+		}
+	}
+	return req
+}
+
+func persistedQueryNotFound(errs GraphQLErrors) bool {
+	for _, e := range errs {
+		if e.Code() == "PERSISTED_QUERY_NOT_FOUND" || e.Message == "PersistedQueryNotFound" {
+			return true
+		}
+	}
+	return false
+}
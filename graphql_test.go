@@ -0,0 +1,104 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestGraphQLPartialData(t *testing.T) {
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"data": {"movements": {"totalCount": 2}},
+			"errors": [{
+				"message": "not authorized",
+				"locations": [{"line": 1, "column": 40}],
+				"path": ["movements", "items", 1, "fee"],
+				"extensions": {"code": "FORBIDDEN"}
+			}]
+		}`)
+	}))
+
+	var out struct {
+		Movements struct {
+			TotalCount int `json:"totalCount"`
+		} `json:"movements"`
+	}
+	err := client.GraphQL(context.Background(), `query { movements { totalCount } }`, nil, &out)
+
+	var gqlErrs gotropipay.GraphQLErrors
+	if !errors.As(err, &gqlErrs) {
+		t.Fatalf("expected GraphQLErrors, got %v", err)
+	}
+	if !gqlErrs.HasCode("FORBIDDEN") || gqlErrs[0].PathString() != "movements.items.1.fee" || gqlErrs[0].Locations[0].Column != 40 {
+		t.Errorf("unexpected errors: %+v", gqlErrs)
+	}
+	if out.Movements.TotalCount != 2 {
+		t.Errorf("partial data was not decoded")
+	}
+}
+
+func TestGraphQLBatchFallback(t *testing.T) {
+	var calls int
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if body[0] == '[' {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]interface{}{"errors": []map[string]string{{"message": "batching not supported"}}})
+			return
+		}
+		var req struct {
+			OperationName string `json:"operationName"`
+		}
+		_ = json.Unmarshal(body, &req)
+		writeJSON(w, map[string]interface{}{"data": map[string]string{"op": req.OperationName}})
+	}))
+
+	ops := []gotropipay.GraphQLOperation{
+		{OperationName: "A", Query: "query A { op }"},
+		{OperationName: "B", Query: "query B { op }"},
+	}
+	var a, b struct{ Op string }
+	errs, err := client.GraphQLBatch(context.Background(), ops, []interface{}{&a, &b})
+	if err != nil || errs[0] != nil || errs[1] != nil {
+		t.Fatalf("batch failed: %v %v", err, errs)
+	}
+	if a.Op != "A" || b.Op != "B" || calls != 3 {
+		t.Errorf("unexpected results a=%q b=%q calls=%d", a.Op, b.Op, calls)
+	}
+}
+
+func TestGraphQLBatchDoesNotReplay(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"server error", http.StatusInternalServerError, `{"errors":[{"message":"boom"}]}`},
+		{"unauthorized", http.StatusUnauthorized, `{"errors":[{"message":"expired"}]}`},
+		{"plain 400", http.StatusBadRequest, "bad request"},
+		{"object on success", http.StatusOK, `{"data":{}}`},
+	} {
+		var calls int
+		client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(tc.status)
+			io.WriteString(w, tc.body)
+		}))
+		ops := []gotropipay.GraphQLOperation{{Query: "mutation { pay }"}, {Query: "mutation { pay }"}}
+		if _, err := client.GraphQLBatch(context.Background(), ops, []interface{}{nil, nil}); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+		// Batching stays enabled and nothing is re-sent
+		if _, err := client.GraphQLBatch(context.Background(), ops, []interface{}{nil, nil}); err == nil || calls != 2 {
+			t.Errorf("%s: %d calls, want 2 batch attempts", tc.name, calls)
+		}
+	}
+}
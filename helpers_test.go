@@ -0,0 +1,33 @@
+package gotropipay_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// newFakeClient returns a client pointed at an httptest server that issues
+// tokens itself and forwards every other request to api
+func newFakeClient(t *testing.T, api http.Handler, opts ...gotropipay.Option) *gotropipay.Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/access/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, gotropipay.TokenResponse{AccessToken: "test-token", ExpiresIn: 3600})
+	})
+	mux.Handle("/", api)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	opts = append([]gotropipay.Option{gotropipay.WithBaseURL(srv.URL)}, opts...)
+	return gotropipay.NewClient("client-id", "client-secret", opts...)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,147 @@
+package gotropipay
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in the smallest currency unit (e.g. cents) with its currency
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// NewMoney creates a Money value from an amount in cents
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney parses a decimal string such as "15.5" or "-3,20" into Money.
+// At most one leading sign is allowed; everything else must be digits.
+func ParseMoney(s, currency string) (Money, error) {
+	in := s
+	s = strings.TrimSpace(strings.Replace(s, ",", ".", 1))
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return Money{}, fmt.Errorf("invalid amount %q: no digits", in)
+	}
+	if !allDigits(whole) || !allDigits(frac) {
+		return Money{}, fmt.Errorf("invalid amount %q: unexpected character", in)
+	}
+	if len(frac) > 2 {
+		return Money{}, fmt.Errorf("invalid amount %q: more than 2 decimals", in)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > (math.MaxInt64-99)/100 {
+		return Money{}, fmt.Errorf("invalid amount %q: out of range", in)
+	}
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+
+	amount := units*100 + cents
+	if neg {
+		amount = -amount
+	}
+	return NewMoney(amount, currency), nil
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmarshalJSON accepts {"amount", "currency"}, the GraphQL {"value",
+// "currency"} shape, or bare cents with no currency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] != '{' {
+		var cents int64
+		if err := json.Unmarshal(data, &cents); err != nil {
+			return err
+		}
+		*m = Money{Amount: cents}
+		return nil
+	}
+	var aux struct {
+		Amount   *int64 `json:"amount"`
+		Value    int64  `json:"value"`
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	amount := aux.Value
+	if aux.Amount != nil {
+		amount = *aux.Amount
+	}
+	*m = NewMoney(amount, aux.Currency)
+	return nil
+}
+
+// String formats the amount as "15.00 EUR"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Decimal formats the amount without currency, e.g. "15.00"
+func (m Money) Decimal() string {
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add returns m + o. Both values must share a currency.
+func (m Money) Add(o Money) (Money, error) {
+	if !m.sameCurrency(o) {
+		return Money{}, fmt.Errorf("currency mismatch: %s + %s", m.Currency, o.Currency)
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.currency(o)}, nil
+}
+
+// Sub returns m - o. Both values must share a currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if !m.sameCurrency(o) {
+		return Money{}, fmt.Errorf("currency mismatch: %s - %s", m.Currency, o.Currency)
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.currency(o)}, nil
+}
+
+// sameCurrency treats an empty currency as compatible with any, so zero values can be summed into
+func (m Money) sameCurrency(o Money) bool {
+	return m.Currency == "" || o.Currency == "" || strings.EqualFold(m.Currency, o.Currency)
+}
+
+func (m Money) currency(o Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return o.Currency
+}
@@ -0,0 +1,29 @@
+package gotropipay_test
+
+import (
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := map[string]int64{"15": 1500, "15.5": 1550, "-3,20": -320, ".07": 7, "0.00": 0, "+2.5": 250, " 4 ": 400, "7.": 700}
+	for in, want := range cases {
+		m, err := gotropipay.ParseMoney(in, "eur")
+		if err != nil {
+			t.Errorf("ParseMoney(%q): %v", in, err)
+			continue
+		}
+		if m.Amount != want || m.Currency != "EUR" {
+			t.Errorf("ParseMoney(%q) = %v, want %d EUR", in, m, want)
+		}
+	}
+	for _, in := range []string{"1.234", "", "-", "+", ".", "--5", "+-5", "1.-5", "1.+5", "1-5", "1.5-", "1 5", "1.2.3", "abc", "1e3", "0x10", "99999999999999999999"} {
+		if m, err := gotropipay.ParseMoney(in, "EUR"); err == nil {
+			t.Errorf("ParseMoney(%q) = %v, want error", in, m)
+		}
+	}
+	if got := gotropipay.NewMoney(-1505, "EUR").String(); got != "-15.05 EUR" {
+		t.Errorf("String() = %q", got)
+	}
+}
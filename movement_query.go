@@ -0,0 +1,422 @@
+package gotropipay
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// MovementField is a selectable field of the business movements GraphQL query
+type MovementField int
+
+const (
+	FieldID              MovementField = iota // id
+	FieldReference                            // reference
+	FieldConcept                              // concept
+	FieldState                                // state
+	FieldCreatedAt                            // createdAt
+	FieldCompletedAt                          // completedAt
+	FieldAmount                               // amount { value currency }
+	FieldSender                               // sender (display name)
+	FieldRecipient                            // recipient (display name)
+	FieldSenderData                           // movementDetail.senderData { name email }
+	FieldRecipientData                        // movementDetail.recipientData { name account }
+	FieldBalances                             // balanceBefore balanceAfter
+	FieldFee                                  // fee { value currency }
+	FieldSenderDetail                         // movementDetail.senderData { account accountType country documentNumber }
+	FieldRecipientDetail                      // movementDetail.recipientData { email accountType country documentNumber }
+	FieldExchangeRate                         // exchangeRate
+	FieldPaymentMethod                        // paymentMethod
+	FieldAccount                              // account { id alias currency }
+	numMovementFields
+)
+
+// movementFieldGQL holds the selection for every field. Party fields are
+// sub-selections merged under movementDetail.senderData / recipientData.
+var movementFieldGQL = map[MovementField]string{
+	FieldID:            "id",
+	FieldReference:     "reference",
+	FieldConcept:       "concept",
+	FieldState:         "state",
+	FieldCreatedAt:     "createdAt",
+	FieldCompletedAt:   "completedAt",
+	FieldAmount:        "amount { value currency }",
+	FieldSender:        "sender",
+	FieldRecipient:     "recipient",
+	FieldSenderData:    "name email",
+	FieldRecipientData: "name account",
+	FieldBalances:      "balanceBefore balanceAfter",
+	FieldFee:           "fee { value currency }",
+
+	FieldSenderDetail:    "account accountType country documentNumber",
+	FieldRecipientDetail: "email accountType country documentNumber",
+	FieldExchangeRate:    "exchangeRate",
+	FieldPaymentMethod:   "paymentMethod",
+	FieldAccount:         "account { id alias currency }",
+}
+
+// DefaultMovementFields is the selection used by SearchMovements and
+// WatchMovements. Balances, fees and the other detail fields are not part of
+// it; add them with MovementQuery.Select where the server exposes them.
+var DefaultMovementFields = []MovementField{
+	FieldID, FieldReference, FieldConcept, FieldState, FieldCreatedAt, FieldCompletedAt,
+	FieldAmount, FieldSender, FieldRecipient, FieldSenderData, FieldRecipientData,
+}
+
+// FilterOption composes a MovementFilter
+type FilterOption func(*MovementFilter)
+
+// StateIn restricts results to the given states
+func StateIn(states ...string) FilterOption {
+	return func(f *MovementFilter) { f.State = append(f.State, states...) }
+}
+
+// CurrencyIs restricts results to a currency
+func CurrencyIs(currency string) FilterOption {
+	return func(f *MovementFilter) { f.Currency = currency }
+}
+
+// AmountBetween restricts amounts (in cents); zero leaves a bound open
+func AmountBetween(gte, lte int64) FilterOption {
+	return func(f *MovementFilter) {
+		f.AmountGte = gte
+		f.AmountLte = lte
+	}
+}
+
+// CreatedBetween restricts createdAt; a zero time leaves a bound open
+func CreatedBetween(from, to time.Time) FilterOption {
+	return func(f *MovementFilter) {
+		if !from.IsZero() {
+			f.CreatedAtFrom = from.UTC().Format(time.RFC3339)
+		}
+		if !to.IsZero() {
+			f.CreatedAtTo = to.UTC().Format(time.RFC3339)
+		}
+	}
+}
+
+// ReferenceIs restricts results to a reference
+func ReferenceIs(reference string) FilterOption {
+	return func(f *MovementFilter) { f.Reference = reference }
+}
+
+// AccountIs restricts results to an account
+func AccountIs(accountID string) FilterOption {
+	return func(f *MovementFilter) { f.AccountID = accountID }
+}
+
+// MovementQuery builds a query against the business movements endpoint
+type MovementQuery struct {
+	selected [numMovementFields]bool
+	filter   *MovementFilter
+	limit    int
+	offset   int
+
+	// after switches the query to keyset pagination in (createdAt, id) order
+	after *Cursor
+}
+
+// NewMovementQuery creates a query selecting the given fields
+func NewMovementQuery(fields ...MovementField) *MovementQuery {
+	q := &MovementQuery{}
+	return q.Select(fields...)
+}
+
+// Select adds fields to the selection
+func (q *MovementQuery) Select(fields ...MovementField) *MovementQuery {
+	for _, f := range fields {
+		if f >= 0 && f < numMovementFields {
+			q.selected[f] = true
+		}
+	}
+	return q
+}
+
+// Where applies filter options on top of any previous ones
+func (q *MovementQuery) Where(opts ...FilterOption) *MovementQuery {
+	if q.filter == nil {
+		q.filter = &MovementFilter{}
+	}
+	for _, opt := range opts {
+		opt(q.filter)
+	}
+	return q
+}
+
+// WithFilter replaces the filter with a copy of f
+func (q *MovementQuery) WithFilter(f *MovementFilter) *MovementQuery {
+	if f == nil {
+		q.filter = nil
+		return q
+	}
+	cp := *f
+	q.filter = &cp
+	return q
+}
+
+// Page sets limit/offset pagination
+func (q *MovementQuery) Page(limit, offset int) *MovementQuery {
+	q.limit = limit
+	q.offset = offset
+	return q
+}
+
+// After switches to keyset pagination, returning rows strictly after cursor in
+// stable (createdAt, id) order. The offset set by Page is ignored.
+func (q *MovementQuery) After(cursor Cursor) *MovementQuery {
+	q.after = &cursor
+	return q.Select(FieldID, FieldCreatedAt)
+}
+
+// Selects reports whether f is part of the selection
+func (q *MovementQuery) Selects(f MovementField) bool {
+	return f >= 0 && f < numMovementFields && q.selected[f]
+}
+
+// String renders the GraphQL document
+func (q *MovementQuery) String() string {
+	var items []string
+	for f := MovementField(0); f < numMovementFields; f++ {
+		if !q.selected[f] || isPartyField(f) {
+			continue
+		}
+		items = append(items, movementFieldGQL[f])
+	}
+	var detail []string
+	if sub := q.partySelection(FieldSenderData, FieldSenderDetail); sub != "" {
+		detail = append(detail, "senderData { "+sub+" }")
+	}
+	if sub := q.partySelection(FieldRecipientData, FieldRecipientDetail); sub != "" {
+		detail = append(detail, "recipientData { "+sub+" }")
+	}
+	if len(detail) > 0 {
+		items = append(items, "movementDetail { "+strings.Join(detail, " ")+" }")
+	}
+	if len(items) == 0 {
+		items = append(items, "id")
+	}
+
+	if q.after != nil {
+		return "query GetMovements($filter: MovementFilter, $pagination: PaginationInput, $orderBy: [MovementOrder!]) { " +
+			"movements(filter: $filter, pagination: $pagination, orderBy: $orderBy) { " +
+			"items { " + strings.Join(items, " ") + " } totalCount } }"
+	}
+
+	return "query GetMovements($filter: MovementFilter, $pagination: PaginationInput) { " +
+		"movements(filter: $filter, pagination: $pagination) { " +
+		"items { " + strings.Join(items, " ") + " } totalCount } }"
+}
+
+func isPartyField(f MovementField) bool {
+	switch f {
+	case FieldSenderData, FieldSenderDetail, FieldRecipientData, FieldRecipientDetail:
+		return true
+	}
+	return false
+}
+
+// partySelection joins the selected sub-fields of one side of movementDetail
+func (q *MovementQuery) partySelection(fields ...MovementField) string {
+	var sub []string
+	for _, f := range fields {
+		if q.selected[f] {
+			sub = append(sub, movementFieldGQL[f])
+		}
+	}
+	return strings.Join(sub, " ")
+}
+
+// Variables returns the GraphQL variables for the query
+func (q *MovementQuery) Variables() map[string]interface{} {
+	if q.after != nil {
+		return q.keysetVariables()
+	}
+	return map[string]interface{}{
+		"filter": q.filter,
+		"pagination": map[string]int{
+			"limit":  q.limit,
+			"offset": q.offset,
+		},
+	}
+}
+
+// keysetVariables starts the scan at the cursor's createdAt (inclusive) and
+// over-fetches the rows already consumed at that instant, which are then
+// dropped client-side
+func (q *MovementQuery) keysetVariables() map[string]interface{} {
+	filter := MovementFilter{}
+	if q.filter != nil {
+		filter = *q.filter
+	}
+	if !q.after.IsZero() {
+		if from, err := time.Parse(time.RFC3339Nano, filter.CreatedAtFrom); err != nil || cursorKey(*q.after).at.After(from) {
+			filter.CreatedAtFrom = q.after.CreatedAt
+		}
+	}
+
+	return map[string]interface{}{
+		"filter": filter,
+		"pagination": map[string]int{
+			"limit":  q.keysetRequested(),
+			"offset": 0,
+		},
+		"orderBy": []map[string]string{
+			{"field": "createdAt", "direction": "ASC"},
+			{"field": "id", "direction": "ASC"},
+		},
+	}
+}
+
+func (q *MovementQuery) keysetRequested() int {
+	if q.after.IsZero() {
+		return q.limit
+	}
+	return q.limit + q.after.Skip
+}
+
+// MovementAmount is an amount as returned by the business endpoint
+type MovementAmount struct {
+	Value    int64  `json:"value"`
+	Currency string `json:"currency"`
+}
+
+// MovementRecord is a typed movement row. Only the fields in the query's
+// selection are populated; use Has to tell an empty value from an unselected one.
+type MovementRecord struct {
+	ID            interface{}      `json:"id"`
+	Reference     string           `json:"reference"`
+	Concept       string           `json:"concept"`
+	State         string           `json:"state"`
+	CreatedAt     string           `json:"createdAt"`
+	CompletedAt   string           `json:"completedAt"`
+	Amount        MovementAmount   `json:"amount"`
+	Sender        string           `json:"sender"`
+	Recipient     string           `json:"recipient"`
+	SenderData    *MovementParty   `json:"senderData,omitempty"`
+	RecipientData *MovementParty   `json:"recipientData,omitempty"`
+	BalanceBefore int64            `json:"balanceBefore"`
+	BalanceAfter  int64            `json:"balanceAfter"`
+	Fee           *MovementAmount  `json:"fee,omitempty"`
+	ExchangeRate  float64          `json:"exchangeRate,omitempty"`
+	PaymentMethod string           `json:"paymentMethod,omitempty"`
+	Account       *MovementAccount `json:"account,omitempty"`
+
+	query *MovementQuery
+}
+
+// UnmarshalJSON flattens the movementDetail object into the record
+func (r *MovementRecord) UnmarshalJSON(data []byte) error {
+	type plain MovementRecord
+	var aux struct {
+		plain
+		MovementDetail struct {
+			SenderData    *MovementParty `json:"senderData"`
+			RecipientData *MovementParty `json:"recipientData"`
+		} `json:"movementDetail"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*r = MovementRecord(aux.plain)
+	if aux.MovementDetail.SenderData != nil {
+		r.SenderData = aux.MovementDetail.SenderData
+	}
+	if aux.MovementDetail.RecipientData != nil {
+		r.RecipientData = aux.MovementDetail.RecipientData
+	}
+	return nil
+}
+
+// Has reports whether f was selected by the query that produced the record
+func (r MovementRecord) Has(f MovementField) bool {
+	return r.query != nil && r.query.Selects(f)
+}
+
+// Movement maps the record onto the standard Movement struct
+func (r MovementRecord) Movement() Movement {
+	m := Movement{
+		ID:            r.ID,
+		Amount:        r.Amount.Value,
+		Currency:      r.Amount.Currency,
+		State:         r.State,
+		Reference:     r.Reference,
+		Concept:       r.Concept,
+		CreatedAt:     r.CreatedAt,
+		CompletedAt:   r.CompletedAt,
+		BalanceBefore: r.BalanceBefore,
+		BalanceAfter:  r.BalanceAfter,
+		ExchangeRate:  r.ExchangeRate,
+		PaymentMethod: r.PaymentMethod,
+		Account:       r.Account,
+	}
+	if r.Fee != nil {
+		m.Fee = NewMoney(r.Fee.Value, r.Fee.Currency)
+	}
+
+	// Prefer the detailed party data, falling back to the display names
+	m.Sender = mergeParty(r.Sender, r.SenderData, r.Has(FieldSender))
+	m.Recipient = mergeParty(r.Recipient, r.RecipientData, r.Has(FieldRecipient))
+	return m
+}
+
+func mergeParty(name string, detail *MovementParty, selected bool) *MovementParty {
+	if detail == nil {
+		if !selected {
+			return nil
+		}
+		return &MovementParty{Name: name}
+	}
+	p := *detail
+	if p.Name == "" {
+		p.Name = name
+	}
+	return &p
+}
+
+// MovementQueryResult is the response of QueryMovements
+type MovementQueryResult struct {
+	Items      []MovementRecord
+	TotalCount int
+
+	// Next and HasMore are only set for keyset queries (see MovementQuery.After)
+	Next    Cursor
+	HasMore bool
+}
+
+// QueryMovements runs a MovementQuery against the GraphQL business endpoint.
+// On a GraphQLErrors error the result still holds any partial data.
+func (c *Client) QueryMovements(ctx context.Context, q *MovementQuery) (*MovementQueryResult, error) {
+	var data struct {
+		Movements struct {
+			Items      []MovementRecord `json:"items"`
+			TotalCount int              `json:"totalCount"`
+		} `json:"movements"`
+	}
+
+	err := c.ExecuteGraphQL(ctx, GraphQLOperation{
+		OperationName: "GetMovements",
+		Query:         q.String(),
+		Variables:     q.Variables(),
+	}, &data)
+	if err != nil {
+		if _, ok := err.(GraphQLErrors); !ok {
+			return nil, err
+		}
+	}
+
+	items := data.Movements.Items
+	for i := range items {
+		items[i].query = q
+	}
+
+	res := &MovementQueryResult{
+		Items:      items,
+		TotalCount: data.Movements.TotalCount,
+	}
+	if q.after != nil {
+		res.Items, res.Next, res.HasMore = applyKeyset(items, *q.after, q.limit, q.keysetRequested())
+	}
+	return res, err
+}
@@ -0,0 +1,55 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestQueryMovementsSelection(t *testing.T) {
+	var gotQuery string
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		gotQuery = req.Query
+		writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"movements": map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{
+				"id":             "m1",
+				"concept":        "Invoice 7",
+				"amount":         map[string]interface{}{"value": 1500, "currency": "EUR"},
+				"movementDetail": map[string]interface{}{"recipientData": map[string]interface{}{"name": "Ana", "account": "ES91"}},
+			}},
+			"totalCount": 1,
+		}}})
+	}))
+
+	q := gotropipay.NewMovementQuery(gotropipay.FieldID, gotropipay.FieldAmount, gotropipay.FieldConcept, gotropipay.FieldRecipientData).
+		Where(gotropipay.StateIn("completed"), gotropipay.CurrencyIs("EUR")).
+		Page(10, 0)
+
+	res, err := client.QueryMovements(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(gotQuery, "senderData") || !strings.Contains(gotQuery, "movementDetail { recipientData { name account } }") {
+		t.Errorf("unexpected query: %s", gotQuery)
+	}
+
+	rec := res.Items[0]
+	if rec.Concept != "Invoice 7" || rec.RecipientData == nil || rec.RecipientData.AccountNumber != "ES91" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+	if m := rec.Movement(); m.Concept != "Invoice 7" || m.Recipient.AccountNumber != "ES91" || m.Sender != nil {
+		t.Errorf("unexpected movement: %+v", m)
+	}
+	if !rec.Has(gotropipay.FieldConcept) || rec.Has(gotropipay.FieldSender) {
+		t.Errorf("selection not reflected in record")
+	}
+}
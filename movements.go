@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // MovementState represents the state of a movement
@@ -18,32 +21,224 @@ const (
 	MovementStateCancelled MovementState = "cancelled"
 )
 
+// IsTerminal reports whether no further state transitions are expected
+func (s MovementState) IsTerminal() bool {
+	switch MovementState(strings.ToLower(string(s))) {
+	case MovementStateCompleted, MovementStateFailed, MovementStateCancelled:
+		return true
+	}
+	return false
+}
+
 // Movement represents a transaction or movement record
 type Movement struct {
-	ID            interface{} `json:"id"` // Can be int or string depending on the endpoint (REST vs GraphQL)
-	Amount        int64       `json:"amount"`
-	Currency      string      `json:"currency"`
-	State         string      `json:"state"` // using string instead of MovementState to be flexible with casing
-	Reference     string      `json:"reference"`
-	CreatedAt     string      `json:"createdAt"`
-	CompletedAt   string      `json:"completedAt"`
-	BalanceBefore int64       `json:"balanceBefore"`
-	BalanceAfter  int64       `json:"balanceAfter"`
-	Recipient     *User       `json:"recipient,omitempty"` // Populated in GraphQL
-	Sender        *User       `json:"sender,omitempty"`    // Populated in GraphQL
-	Account       interface{} `json:"account,omitempty"`   // Populated in GraphQL generally
+	ID            interface{}      `json:"id"` // Can be int or string depending on the endpoint (REST vs GraphQL)
+	Amount        int64            `json:"amount"`
+	Currency      string           `json:"currency"`
+	State         string           `json:"state"` // using string instead of MovementState to be flexible with casing
+	Reference     string           `json:"reference"`
+	Concept       string           `json:"concept,omitempty"`
+	CreatedAt     string           `json:"createdAt"`
+	CompletedAt   string           `json:"completedAt"`
+	BalanceBefore int64            `json:"balanceBefore"`
+	BalanceAfter  int64            `json:"balanceAfter"`
+	Fee           Money            `json:"fee"`                    // REST may send bare cents, taken to be in Currency
+	ExchangeRate  float64          `json:"exchangeRate,omitempty"` // Set when the movement involved a conversion
+	PaymentMethod string           `json:"paymentMethod,omitempty"`
+	Recipient     *MovementParty   `json:"recipient,omitempty"`
+	Sender        *MovementParty   `json:"sender,omitempty"`
+	Account       *MovementAccount `json:"account,omitempty"`
+	CardToken     *CardToken       `json:"cardToken,omitempty"` // Set on paylink payments made with SaveToken
+}
+
+// UnmarshalJSON fills in the fee currency when the endpoint only sends cents
+func (m *Movement) UnmarshalJSON(data []byte) error {
+	type plain Movement
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+	if m.Fee.Currency == "" && m.Fee.Amount != 0 {
+		m.Fee.Currency = strings.ToUpper(m.Currency)
+	}
+	return nil
+}
+
+// MovementParty is the sender or recipient side of a movement
+type MovementParty struct {
+	Name           string `json:"name"`
+	Email          string `json:"email,omitempty"`
+	AccountNumber  string `json:"accountNumber,omitempty"`
+	AccountType    string `json:"accountType,omitempty"`
+	Country        string `json:"country,omitempty"`
+	DocumentNumber string `json:"documentNumber,omitempty"`
+}
+
+// UnmarshalJSON accepts either a plain name string or a full object
+func (p *MovementParty) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = MovementParty{Name: name}
+		return nil
+	}
+	type plain MovementParty
+	var aux struct {
+		plain
+		Account string `json:"account"` // GraphQL name for the account number
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*p = MovementParty(aux.plain)
+	if p.AccountNumber == "" {
+		p.AccountNumber = aux.Account
+	}
+	return nil
+}
+
+// MovementAccount identifies the Tropipay account a movement was booked on
+type MovementAccount struct {
+	ID       string `json:"id"`
+	Alias    string `json:"alias,omitempty"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// UnmarshalJSON accepts a bare ID (number or string) or a full object
+func (a *MovementAccount) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '{' {
+		id, err := flexibleID(data)
+		if err != nil {
+			return err
+		}
+		*a = MovementAccount{ID: id}
+		return nil
+	}
+	var aux struct {
+		ID       json.RawMessage `json:"id"`
+		Alias    string          `json:"alias"`
+		Currency string          `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	id, err := flexibleID(aux.ID)
+	if err != nil {
+		return err
+	}
+	*a = MovementAccount{ID: id, Alias: aux.Alias, Currency: aux.Currency}
+	return nil
+}
+
+// flexibleID decodes a JSON string or number into its string form
+func flexibleID(data json.RawMessage) (string, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return "", nil
+	}
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	return Movement{ID: v}.IDString(), nil
+}
+
+// IDString returns the movement ID as a string regardless of its JSON type
+func (m Movement) IDString() string {
+	switch id := m.ID.(type) {
+	case nil:
+		return ""
+	case string:
+		return id
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	case json.Number:
+		return id.String()
+	default:
+		return fmt.Sprint(id)
+	}
+}
+
+// SignedAmount returns credits as positive and debits as negative amounts.
+// The balance delta is authoritative when present; Amount is used otherwise.
+func (m Movement) SignedAmount() int64 {
+	if m.BalanceAfter != m.BalanceBefore {
+		return m.BalanceAfter - m.BalanceBefore
+	}
+	return m.Amount
+}
+
+// BookingTime returns the completion time, falling back to creation time, or
+// the zero time if neither parses
+func (m Movement) BookingTime() time.Time {
+	for _, s := range []string{m.CompletedAt, m.CreatedAt} {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// SenderName returns the trimmed sender name, or "" if there is no sender
+func (m Movement) SenderName() string {
+	return m.Sender.name()
+}
+
+// RecipientName returns the trimmed recipient name, or "" if there is no recipient
+func (m Movement) RecipientName() string {
+	return m.Recipient.name()
+}
+
+func (p *MovementParty) name() string {
+	if p == nil {
+		return ""
+	}
+	return strings.TrimSpace(p.Name)
 }
 
 // MovementFilter represents the filter criteria for listing movements
 type MovementFilter struct {
-	State         []string `json:"state,omitempty"`
-	Currency      string   `json:"currency,omitempty"`
-	AmountGte     int64    `json:"amountGte,omitempty"`
-	AmountLte     int64    `json:"amountLte,omitempty"`
-	CreatedAtFrom string   `json:"createdAtFrom,omitempty"`
-	CreatedAtTo   string   `json:"createdAtTo,omitempty"`
-	Reference     string   `json:"reference,omitempty"`
-	AccountID     string   `json:"accountId,omitempty"` // For GraphQL filter
+	State           []string `json:"state,omitempty"`
+	Currency        string   `json:"currency,omitempty"`
+	AmountGte       int64    `json:"amountGte,omitempty"`
+	AmountLte       int64    `json:"amountLte,omitempty"`
+	CreatedAtFrom   string   `json:"createdAtFrom,omitempty"`
+	CreatedAtTo     string   `json:"createdAtTo,omitempty"`
+	CompletedAtFrom string   `json:"completedAtFrom,omitempty"`
+	Reference       string   `json:"reference,omitempty"`
+	AccountID       string   `json:"accountId,omitempty"` // For GraphQL filter
+}
+
+// matches applies the filter criteria that can be checked client-side.
+// Date bounds are left to the server. A nil filter matches everything.
+func (f *MovementFilter) matches(m Movement) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.State) > 0 {
+		found := false
+		for _, s := range f.State {
+			if strings.EqualFold(s, m.State) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Currency != "" && !strings.EqualFold(f.Currency, m.Currency) {
+		return false
+	}
+	if f.AmountGte > 0 && m.Amount < f.AmountGte {
+		return false
+	}
+	if f.AmountLte > 0 && m.Amount > f.AmountLte {
+		return false
+	}
+	if f.Reference != "" && f.Reference != m.Reference {
+		return false
+	}
+	return true
 }
 
 // ListMovementsResponse is the response structure for listing movements
@@ -53,12 +248,6 @@ type ListMovementsResponse struct {
 	HasMore    bool       `json:"hasMore"`
 }
 
-// GraphQLRequest structures
-type graphQLRequest struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables,omitempty"`
-}
-
 // REST Endpoints
 
 // ListMovements retrieves a list of movements for the authenticated user
@@ -100,137 +289,59 @@ func (c *Client) listMovementsCommon(ctx context.Context, path string, limit, of
 	return &resp, nil
 }
 
+// GetMovement retrieves a single movement
+func (c *Client) GetMovement(ctx context.Context, id string) (*Movement, error) {
+	var m Movement
+	err := c.Request(ctx, "GET", "/movements/"+url.PathEscape(id), nil, &m)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// AllMovements iterates over every movement matching filter, fetching pages lazily.
+// Iteration stops at the first error, which is yielded with a zero Movement.
+func (c *Client) AllMovements(ctx context.Context, filter *MovementFilter) iter.Seq2[Movement, error] {
+	return func(yield func(Movement, error) bool) {
+		const pageSize = 100
+		for offset := 0; ; offset += pageSize {
+			resp, err := c.ListMovements(ctx, pageSize, offset, filter)
+			if err != nil {
+				yield(Movement{}, err)
+				return
+			}
+			for _, m := range resp.Items {
+				if !yield(m, nil) {
+					return
+				}
+			}
+			if len(resp.Items) < pageSize || (resp.TotalCount > 0 && offset+pageSize >= resp.TotalCount) {
+				return
+			}
+		}
+	}
+}
+
 // GraphQL Business Endpoints
 
 // SearchMovements performs an advanced search using the GraphQL endpoint
 func (c *Client) SearchMovements(ctx context.Context, filter *MovementFilter, limit, offset int) (*ListMovementsResponse, error) {
-	query := `query GetMovements($filter: MovementFilter, $pagination: PaginationInput) { 
-		movements(filter: $filter, pagination: $pagination) { 
-			items { 
-				id 
-				reference
-				concept
-				state
-				createdAt 
-				completedAt 
-				amount {
-					value
-					currency
-				}
-				sender
-				recipient
-				movementDetail {
-					senderData {
-						name
-						email
-					}
-					recipientData {
-						name
-						account
-					}
-				}
-			} 
-			totalCount 
-		} 
-	}`
-
-	vars := map[string]interface{}{
-		"filter": filter,
-		"pagination": map[string]int{
-			"limit":  limit,
-			"offset": offset,
-		},
-	}
-
-	req := graphQLRequest{
-		Query:     query,
-		Variables: vars,
-	}
-
-	// Internal structs for GraphQL response
-	type gqlAmount struct {
-		Value    int64  `json:"value"`
-		Currency string `json:"currency"`
-	}
-	type gqlPersonaData struct {
-		Name  string `json:"name"`
-		Email string `json:"email"`
-	}
-	type gqlRecipientData struct {
-		Name    string `json:"name"`
-		Account string `json:"account"`
-	}
-	type gqlMovementDetail struct {
-		SenderData    gqlPersonaData   `json:"senderData"`
-		RecipientData gqlRecipientData `json:"recipientData"`
-	}
-	type gqlMovement struct {
-		ID             interface{}       `json:"id"`
-		Reference      string            `json:"reference"`
-		Concept        string            `json:"concept"`
-		State          string            `json:"state"`
-		CreatedAt      string            `json:"createdAt"`
-		CompletedAt    string            `json:"completedAt"`
-		Amount         gqlAmount         `json:"amount"`
-		Sender         string            `json:"sender"`
-		Recipient      string            `json:"recipient"`
-		MovementDetail gqlMovementDetail `json:"movementDetail"`
-	}
-
-	var gqlResp struct {
-		Data struct {
-			Movements struct {
-				Items      []gqlMovement `json:"items"`
-				TotalCount int           `json:"totalCount"`
-			} `json:"movements"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
-	}
-
-	err := c.Request(ctx, "POST", "/movements/business", req, &gqlResp)
+	q := NewMovementQuery(DefaultMovementFields...).WithFilter(filter).Page(limit, offset)
+
+	res, err := c.QueryMovements(ctx, q)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(gqlResp.Errors) > 0 {
-		return nil, fmt.Errorf("graphql error: %s", gqlResp.Errors[0].Message)
-	}
-
 	// Map back to standard Movement struct
 	var movements []Movement
-	for _, item := range gqlResp.Data.Movements.Items {
-		// Construct User objects from details if available, otherwise just use names
-		sender := &User{Name: item.Sender}
-		if item.MovementDetail.SenderData.Name != "" {
-			sender.Name = item.MovementDetail.SenderData.Name
-			sender.Email = item.MovementDetail.SenderData.Email
-		}
-
-		recipient := &User{Name: item.Recipient}
-		if item.MovementDetail.RecipientData.Name != "" {
-			recipient.Name = item.MovementDetail.RecipientData.Name
-			// item.MovementDetail.RecipientData.Account contains account number usually
-		}
-
-		movements = append(movements, Movement{
-			ID:          item.ID,
-			Amount:      item.Amount.Value,
-			Currency:    item.Amount.Currency,
-			State:       item.State,
-			Reference:   item.Reference,
-			CreatedAt:   item.CreatedAt,
-			CompletedAt: item.CompletedAt,
-			// Balance fields are not in the standard GQL item response unless added, skipping for now
-			Sender:    sender,
-			Recipient: recipient,
-		})
+	for _, item := range res.Items {
+		movements = append(movements, item.Movement())
 	}
 
 	return &ListMovementsResponse{
 		Items:      movements,
-		TotalCount: gqlResp.Data.Movements.TotalCount,
-		HasMore:    len(movements) < gqlResp.Data.Movements.TotalCount, // Approximate
+		TotalCount: res.TotalCount,
+		HasMore:    len(movements) < res.TotalCount, // Approximate
 	}, nil
 }
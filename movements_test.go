@@ -0,0 +1,135 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestMovementDecodesRESTParties(t *testing.T) {
+	data := `{
+		"id": 42, "amount": 1500, "currency": "EUR", "state": "completed", "concept": "Invoice 7",
+		"fee": 53, "exchangeRate": 1.08, "paymentMethod": "CARD",
+		"sender": "Walk-in customer",
+		"recipient": {"name": "Ana López", "email": "ana@example.com", "account": "ES9121000418450200051332", "country": "ES"},
+		"account": 977
+	}`
+
+	var m gotropipay.Movement
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Sender == nil || m.Sender.Name != "Walk-in customer" {
+		t.Errorf("sender: %+v", m.Sender)
+	}
+	if m.Recipient.AccountNumber != "ES9121000418450200051332" || m.Recipient.Country != "ES" {
+		t.Errorf("recipient: %+v", m.Recipient)
+	}
+	if m.Account == nil || m.Account.ID != "977" {
+		t.Errorf("account: %+v", m.Account)
+	}
+	if m.Concept != "Invoice 7" || m.Fee != gotropipay.NewMoney(53, "EUR") || m.PaymentMethod != "CARD" {
+		t.Errorf("movement: %+v", m)
+	}
+}
+
+func TestRESTAndGraphQLDecodeSameMovement(t *testing.T) {
+	const rest = `{"items": [{
+		"id": 42, "reference": "ORD-1", "concept": "Invoice 7", "state": "completed",
+		"createdAt": "2026-10-01T10:00:00Z", "completedAt": "2026-10-01T10:05:00Z",
+		"amount": 1500, "currency": "EUR", "fee": 53, "balanceBefore": 100, "balanceAfter": 1547,
+		"exchangeRate": 1.08, "paymentMethod": "CARD",
+		"sender": {"name": "Luis Pérez", "email": "luis@example.com", "account": "4111", "accountType": "card", "country": "ES", "documentNumber": "X1"},
+		"recipient": {"name": "Ana López", "email": "ana@example.com", "account": "ES91", "accountType": "iban", "country": "ES", "documentNumber": "Y2"},
+		"account": {"id": 977, "alias": "Main", "currency": "EUR"}
+	}]}`
+	const gql = `{"data": {"movements": {"totalCount": 1, "items": [{
+		"id": 42, "reference": "ORD-1", "concept": "Invoice 7", "state": "completed",
+		"createdAt": "2026-10-01T10:00:00Z", "completedAt": "2026-10-01T10:05:00Z",
+		"amount": {"value": 1500, "currency": "EUR"}, "fee": {"value": 53, "currency": "EUR"},
+		"balanceBefore": 100, "balanceAfter": 1547, "exchangeRate": 1.08, "paymentMethod": "CARD",
+		"sender": "Luis Pérez", "recipient": "Ana López",
+		"movementDetail": {
+			"senderData": {"name": "Luis Pérez", "email": "luis@example.com", "account": "4111", "accountType": "card", "country": "ES", "documentNumber": "X1"},
+			"recipientData": {"name": "Ana López", "account": "ES91", "email": "ana@example.com", "accountType": "iban", "country": "ES", "documentNumber": "Y2"}
+		},
+		"account": {"id": "977", "alias": "Main", "currency": "EUR"}
+	}]}}}`
+
+	var query string
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/movements/" {
+			io.WriteString(w, rest)
+			return
+		}
+		var op struct{ Query string }
+		json.NewDecoder(r.Body).Decode(&op)
+		query = op.Query
+		io.WriteString(w, gql)
+	}))
+	ctx := context.Background()
+
+	fromREST, err := client.ListMovements(ctx, 1, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The default selection stays on the fields every server knows
+	if _, err := client.SearchMovements(ctx, nil, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	extra := []string{"fee {", "balanceBefore", "exchangeRate", "paymentMethod", "account { id", "documentNumber"}
+	for _, sel := range extra {
+		if strings.Contains(query, sel) {
+			t.Errorf("default selection has %q: %s", sel, query)
+		}
+	}
+
+	// Selecting the detail fields fills in the same Movement as REST
+	q := gotropipay.NewMovementQuery(gotropipay.DefaultMovementFields...).Select(
+		gotropipay.FieldBalances, gotropipay.FieldFee, gotropipay.FieldSenderDetail, gotropipay.FieldRecipientDetail,
+		gotropipay.FieldExchangeRate, gotropipay.FieldPaymentMethod, gotropipay.FieldAccount)
+	res, err := client.QueryMovements(ctx, q.Page(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sel := range extra {
+		if !strings.Contains(query, sel) {
+			t.Errorf("selection lacks %q: %s", sel, query)
+		}
+	}
+	fromGQL := &gotropipay.ListMovementsResponse{Items: []gotropipay.Movement{res.Items[0].Movement()}}
+	a, b := fromREST.Items[0], fromGQL.Items[0]
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("REST and GraphQL differ:\nREST    %#v\nGraphQL %#v", a, b)
+	}
+	if a.Fee != gotropipay.NewMoney(53, "EUR") || a.Account == nil || a.Account.Alias != "Main" {
+		t.Errorf("movement = %+v", a)
+	}
+}
+
+func TestMovementDerivedFields(t *testing.T) {
+	debit := gotropipay.Movement{Amount: 1500, BalanceBefore: 10000, BalanceAfter: 8500, CreatedAt: "2026-10-01T10:00:00+02:00",
+		Recipient: &gotropipay.MovementParty{Name: " Ana "}}
+	if debit.SignedAmount() != -1500 || debit.RecipientName() != "Ana" || debit.SenderName() != "" {
+		t.Errorf("debit: %d %q %q", debit.SignedAmount(), debit.RecipientName(), debit.SenderName())
+	}
+	if got := debit.BookingTime(); !got.Equal(time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("booking time = %v", got)
+	}
+	debit.CompletedAt = "2026-10-02T10:00:00Z"
+	if got := debit.BookingTime(); got.Day() != 2 {
+		t.Errorf("booking time = %v, want completion", got)
+	}
+	if (gotropipay.Movement{Amount: 300}).SignedAmount() != 300 || !(gotropipay.Movement{}).BookingTime().IsZero() {
+		t.Error("fallbacks")
+	}
+}
@@ -47,3 +47,25 @@ func WithTimeout(d time.Duration) Option {
 		c.httpClient.Timeout = d
 	}
 }
+
+// WithGraphQLPath sets the path of the GraphQL endpoint used by GraphQL and SearchMovements
+func WithGraphQLPath(path string) Option {
+	return func(c *Client) {
+		c.graphQLPath = path
+	}
+}
+
+// WithRateCacheTTL sets how long exchange rates are cached. Zero disables caching.
+func WithRateCacheTTL(d time.Duration) Option {
+	return func(c *Client) {
+		c.rateTTL = d
+	}
+}
+
+// WithSecurityCodeProvider sets the provider asked for a security code whenever
+// a sensitive operation (payouts, beneficiary deletion) is called without one
+func WithSecurityCodeProvider(p SecurityCodeProvider) Option {
+	return func(c *Client) {
+		c.securityCodes = p
+	}
+}
@@ -2,6 +2,7 @@ package gotropipay
 
 import (
 	"context"
+	"errors"
 	"fmt"
 )
 
@@ -20,7 +21,7 @@ type PaymentCard struct {
 	UserID                string      `json:"userId"`
 	QRImage               string      `json:"qrImage"` // Using string, json decoder handles null as "" often or use *string
 	ShortURL              string      `json:"shortUrl"`
-	State                 int         `json:"state"`
+	State                 int         `json:"state"` // One of the PaymentCardState values
 	ExpirationDays        int         `json:"expirationDays"`
 	Lang                  string      `json:"lang"`
 	URLSuccess            string      `json:"urlSuccess"`
@@ -45,16 +46,55 @@ type PaymentCard struct {
 	UpdatedAt             string      `json:"updatedAt"`
 }
 
+// Paylink states reported in PaymentCard.State
+const (
+	PaymentCardStateActive    = 1 // Open, awaiting payment
+	PaymentCardStatePaid      = 2 // Paid; single-use paylinks close here
+	PaymentCardStateExpired   = 3
+	PaymentCardStateCancelled = 4
+)
+
 // CreatePaymentCardRequest represents the payload to create a card
 type CreatePaymentCardRequest struct {
-	Number      string `json:"number"`
-	CVC         string `json:"cvc"`
-	HolderName  string `json:"holderName"`
-	ExpiryMonth int    `json:"expiryMonth"`
-	ExpiryYear  int    `json:"expiryYear"`
+	Number      SensitiveString `json:"number"` // Wiped after the request is sent
+	CVC         SensitiveString `json:"cvc"`    // Wiped after the request is sent
+	HolderName  string          `json:"holderName"`
+	ExpiryMonth int             `json:"expiryMonth"`
+	ExpiryYear  int             `json:"expiryYear"`
 }
 
-// CreatePaymentCard adds a new payment card
+// CreatePaylinkRequest represents the payload to create a paylink
+type CreatePaylinkRequest struct {
+	Reference       string `json:"reference"`
+	Concept         string `json:"concept"`
+	Description     string `json:"description,omitempty"`
+	Amount          int64  `json:"amount"` // In cents
+	Currency        string `json:"currency"`
+	SingleUse       bool   `json:"singleUse"`
+	ReasonID        int    `json:"reasonId,omitempty"`
+	ExpirationDays  int    `json:"expirationDays,omitempty"`
+	Lang            string `json:"lang,omitempty"`
+	URLSuccess      string `json:"urlSuccess,omitempty"`
+	URLFailed       string `json:"urlFailed,omitempty"`
+	URLNotification string `json:"urlNotification,omitempty"`
+	SaveToken       bool   `json:"saveToken,omitempty"` // Keep the card on file for ChargeSavedCard
+}
+
+func (r CreatePaymentCardRequest) wireBody() interface{} {
+	type plain CreatePaymentCardRequest
+	return struct {
+		plain
+		Number wireSecret `json:"number"`
+		CVC    wireSecret `json:"cvc"`
+	}{plain(r), wireSecret(r.Number), wireSecret(r.CVC)}
+}
+
+func (r CreatePaymentCardRequest) secrets() []SensitiveString {
+	return []SensitiveString{r.Number, r.CVC}
+}
+
+// CreatePaymentCard adds a new payment card. The card number and CVC are
+// wiped once sent, so req cannot be reused; build a new one to retry.
 func (c *Client) CreatePaymentCard(ctx context.Context, req CreatePaymentCardRequest) (*PaymentCard, error) {
 	var card PaymentCard
 	// Assuming endpoint is /paymentcards
@@ -65,6 +105,22 @@ func (c *Client) CreatePaymentCard(ctx context.Context, req CreatePaymentCardReq
 	return &card, nil
 }
 
+// CreatePaylink creates a payment link for a customer to pay
+func (c *Client) CreatePaylink(ctx context.Context, req CreatePaylinkRequest) (*PaymentCard, error) {
+	if req.Reference == "" {
+		return nil, errors.New("paylink reference is required")
+	}
+	if req.Amount <= 0 || req.Currency == "" {
+		return nil, fmt.Errorf("invalid paylink amount %s", NewMoney(req.Amount, req.Currency))
+	}
+	var card PaymentCard
+	err := c.Request(ctx, "POST", "/paymentcards", req, &card)
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
 // GetPaymentCard retrieves a specific payment card
 func (c *Client) GetPaymentCard(ctx context.Context, id string) (*PaymentCard, error) {
 	var card PaymentCard
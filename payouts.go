@@ -0,0 +1,225 @@
+package gotropipay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PayoutState represents the state of an outgoing transfer
+type PayoutState string
+
+const (
+	PayoutStatePending    PayoutState = "pending"
+	PayoutStateProcessing PayoutState = "processing"
+	PayoutStateCompleted  PayoutState = "completed"
+	PayoutStateCancelled  PayoutState = "cancelled"
+	PayoutStateFailed     PayoutState = "failed"
+)
+
+// IsTerminal reports whether no further state transitions are expected
+func (s PayoutState) IsTerminal() bool {
+	switch PayoutState(strings.ToLower(string(s))) {
+	case PayoutStateCompleted, PayoutStateCancelled, PayoutStateFailed:
+		return true
+	}
+	return false
+}
+
+// ErrPayoutNotCancellable is returned by CancelPayout when the payout is no longer pending
+var ErrPayoutNotCancellable = errors.New("payout is not pending and cannot be cancelled")
+
+// PayoutRequest describes a transfer to a beneficiary
+type PayoutRequest struct {
+	Beneficiary  DepositAccount // Only ID is required
+	Amount       Money          // Amount debited, in the source account's currency
+	AccountID    int64          // Source account; 0 uses the default account for Amount.Currency
+	Concept      string
+	ReasonID     int
+	Reference    string
+	SecurityCode string // Required by CreatePayout, ignored by SimulatePayout
+
+	// IdempotencyKey, if set, is sent as the Idempotency-Key header so a
+	// retried CreatePayout never books the transfer twice
+	IdempotencyKey string
+}
+
+// payoutPayload is the wire format of PayoutRequest
+type payoutPayload struct {
+	DepositAccountID int    `json:"depositaccountId"`
+	AccountID        int64  `json:"accountId,omitempty"`
+	Amount           int64  `json:"amount"`
+	Currency         string `json:"currency"`
+	Concept          string `json:"concept,omitempty"`
+	ReasonID         int    `json:"reasonId,omitempty"`
+	Reference        string `json:"reference,omitempty"`
+	SecurityCode     string `json:"securityCode,omitempty"`
+}
+
+func (r PayoutRequest) payload(withCode bool) (payoutPayload, error) {
+	if r.Beneficiary.ID == 0 {
+		return payoutPayload{}, errors.New("payout beneficiary ID is required")
+	}
+	if r.Amount.Amount <= 0 || r.Amount.Currency == "" {
+		return payoutPayload{}, fmt.Errorf("invalid payout amount %s", r.Amount)
+	}
+	p := payoutPayload{
+		DepositAccountID: r.Beneficiary.ID,
+		AccountID:        r.AccountID,
+		Amount:           r.Amount.Amount,
+		Currency:         r.Amount.Currency,
+		Concept:          r.Concept,
+		ReasonID:         r.ReasonID,
+		Reference:        r.Reference,
+	}
+	if withCode {
+		p.SecurityCode = r.SecurityCode
+	}
+	return p, nil
+}
+
+// PayoutSimulation is the quote for a payout before it is booked
+type PayoutSimulation struct {
+	Amount         Money   // Amount debited before fees
+	Fee            Money   // Fees charged on top of Amount
+	Total          Money   // Amount + Fee, debited from the source account
+	AmountReceived Money   // What the beneficiary receives, in the destination currency
+	ExchangeRate   float64 // 1 when no conversion applies
+}
+
+// Payout is a booked outgoing transfer
+type Payout struct {
+	ID               string
+	Reference        string
+	State            PayoutState
+	Amount           Money
+	Fee              Money
+	AmountReceived   Money
+	ExchangeRate     float64
+	DepositAccountID int
+	CreatedAt        string
+	UpdatedAt        string
+}
+
+// payoutResponse is the wire format shared by booking responses
+type payoutResponse struct {
+	ID                  interface{} `json:"id"`
+	Reference           string      `json:"reference"`
+	State               string      `json:"state"`
+	Amount              int64       `json:"amount"`
+	Currency            string      `json:"currency"`
+	Fee                 int64       `json:"fee"`
+	DestinationAmount   int64       `json:"destinationAmount"`
+	DestinationCurrency string      `json:"destinationCurrency"`
+	ConversionRate      float64     `json:"conversionRate"`
+	DepositAccountID    int         `json:"depositaccountId"`
+	CreatedAt           string      `json:"createdAt"`
+	UpdatedAt           string      `json:"updatedAt"`
+}
+
+func (r payoutResponse) simulation() *PayoutSimulation {
+	rate := r.ConversionRate
+	if rate == 0 {
+		rate = 1
+	}
+	received := NewMoney(r.DestinationAmount, r.DestinationCurrency)
+	if received.Currency == "" {
+		received = NewMoney(r.Amount, r.Currency)
+	}
+	return &PayoutSimulation{
+		Amount:         NewMoney(r.Amount, r.Currency),
+		Fee:            NewMoney(r.Fee, r.Currency),
+		Total:          NewMoney(r.Amount+r.Fee, r.Currency),
+		AmountReceived: received,
+		ExchangeRate:   rate,
+	}
+}
+
+func (r payoutResponse) payout() *Payout {
+	sim := r.simulation()
+	return &Payout{
+		ID:               Movement{ID: r.ID}.IDString(),
+		Reference:        r.Reference,
+		State:            PayoutState(strings.ToLower(r.State)),
+		Amount:           sim.Amount,
+		Fee:              sim.Fee,
+		AmountReceived:   sim.AmountReceived,
+		ExchangeRate:     sim.ExchangeRate,
+		DepositAccountID: r.DepositAccountID,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
+	}
+}
+
+// SimulatePayout quotes fees, exchange rate and the amount the beneficiary receives
+func (c *Client) SimulatePayout(ctx context.Context, req PayoutRequest) (*PayoutSimulation, error) {
+	payload, err := req.payload(false)
+	if err != nil {
+		return nil, err
+	}
+	var resp payoutResponse
+	err = c.Request(ctx, "POST", "/booking/payout/simulate", payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.simulation(), nil
+}
+
+// CreatePayout books a transfer to the beneficiary. When req.SecurityCode is
+// empty the code is obtained from the client's SecurityCodeProvider.
+func (c *Client) CreatePayout(ctx context.Context, req PayoutRequest) (*Payout, error) {
+	// Validate before asking anyone for a code
+	payload, err := req.payload(true)
+	if err != nil {
+		return nil, err
+	}
+	payload.SecurityCode, err = c.securityCode(ctx, req.SecurityCode, SecurityCodeRequest{
+		Operation: "createPayout",
+		Resource:  req.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var header http.Header
+	if req.IdempotencyKey != "" {
+		header = http.Header{"Idempotency-Key": {req.IdempotencyKey}}
+	}
+
+	var resp payoutResponse
+	err = c.requestWithHeaders(ctx, "POST", "/booking/payout", header, payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.payout(), nil
+}
+
+// GetPayout retrieves the current status of a payout
+func (c *Client) GetPayout(ctx context.Context, id string) (*Payout, error) {
+	var resp payoutResponse
+	err := c.Request(ctx, "GET", "/booking/"+url.PathEscape(id), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.payout(), nil
+}
+
+// CancelPayout cancels a payout that is still pending
+func (c *Client) CancelPayout(ctx context.Context, id string) (*Payout, error) {
+	current, err := c.GetPayout(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.State != PayoutStatePending {
+		return current, ErrPayoutNotCancellable
+	}
+
+	var resp payoutResponse
+	err = c.Request(ctx, "POST", "/booking/"+url.PathEscape(id)+"/cancel", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.payout(), nil
+}
@@ -0,0 +1,198 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestSimulatePayout(t *testing.T) {
+	var payload map[string]interface{}
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/booking/payout/simulate" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		writeJSON(w, map[string]interface{}{
+			"amount": 10000, "currency": "EUR", "fee": 150,
+			"destinationAmount": 10800, "destinationCurrency": "usd", "conversionRate": 1.08,
+		})
+	}))
+
+	sim, err := client.SimulatePayout(context.Background(), gotropipay.PayoutRequest{
+		Beneficiary:  gotropipay.DepositAccount{ID: 42},
+		Amount:       gotropipay.NewMoney(10000, "EUR"),
+		AccountID:    7,
+		Concept:      "Invoice 12",
+		ReasonID:     3,
+		Reference:    "PAY-1",
+		SecurityCode: "123456",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"depositaccountId": float64(42), "accountId": float64(7), "amount": float64(10000), "currency": "EUR",
+		"concept": "Invoice 12", "reasonId": float64(3), "reference": "PAY-1",
+	}
+	for k, v := range want {
+		if payload[k] != v {
+			t.Errorf("payload[%s] = %v, want %v", k, payload[k], v)
+		}
+	}
+	if _, ok := payload["securityCode"]; ok {
+		t.Error("simulation must not send the security code")
+	}
+	if sim.Fee != gotropipay.NewMoney(150, "EUR") || sim.Total != gotropipay.NewMoney(10150, "EUR") ||
+		sim.AmountReceived != gotropipay.NewMoney(10800, "USD") || sim.ExchangeRate != 1.08 {
+		t.Errorf("simulation = %+v", sim)
+	}
+}
+
+func TestSimulatePayoutDefaults(t *testing.T) {
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"amount": 500, "currency": "EUR"})
+	}))
+	sim, err := client.SimulatePayout(context.Background(), gotropipay.PayoutRequest{
+		Beneficiary: gotropipay.DepositAccount{ID: 1}, Amount: gotropipay.NewMoney(500, "EUR"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.ExchangeRate != 1 || sim.AmountReceived != gotropipay.NewMoney(500, "EUR") || sim.Total != sim.Amount {
+		t.Errorf("simulation = %+v", sim)
+	}
+}
+
+func TestPayoutRequestValidation(t *testing.T) {
+	calls := 0
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	ctx := context.Background()
+	for _, req := range []gotropipay.PayoutRequest{
+		{Amount: gotropipay.NewMoney(100, "EUR")},
+		{Beneficiary: gotropipay.DepositAccount{ID: 1}, Amount: gotropipay.NewMoney(0, "EUR")},
+		{Beneficiary: gotropipay.DepositAccount{ID: 1}, Amount: gotropipay.NewMoney(100, "")},
+	} {
+		if _, err := client.SimulatePayout(ctx, req); err == nil {
+			t.Errorf("SimulatePayout(%+v): expected error", req)
+		}
+		req.SecurityCode = "123456"
+		if _, err := client.CreatePayout(ctx, req); err == nil {
+			t.Errorf("CreatePayout(%+v): expected error", req)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("%d requests sent for invalid payouts", calls)
+	}
+}
+
+func TestCreatePayout(t *testing.T) {
+	var payload map[string]interface{}
+	var key string
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/booking/payout" {
+			http.NotFound(w, r)
+			return
+		}
+		key = r.Header.Get("Idempotency-Key")
+		json.NewDecoder(r.Body).Decode(&payload)
+		writeJSON(w, map[string]interface{}{
+			"id": 991, "reference": "PAY-1", "state": "Pending", "amount": 2000, "currency": "EUR",
+			"fee": 30, "depositaccountId": 42, "createdAt": "2026-10-01T10:00:00Z",
+		})
+	}))
+
+	p, err := client.CreatePayout(context.Background(), gotropipay.PayoutRequest{
+		Beneficiary:    gotropipay.DepositAccount{ID: 42},
+		Amount:         gotropipay.NewMoney(2000, "EUR"),
+		Reference:      "PAY-1",
+		SecurityCode:   "123456",
+		IdempotencyKey: "key-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload["securityCode"] != "123456" || key != "key-1" {
+		t.Errorf("payload = %v, key = %q", payload, key)
+	}
+	if p.ID != "991" || p.State != gotropipay.PayoutStatePending || p.Fee != gotropipay.NewMoney(30, "EUR") ||
+		p.Amount != gotropipay.NewMoney(2000, "EUR") || p.DepositAccountID != 42 {
+		t.Errorf("payout = %+v", p)
+	}
+}
+
+func TestCreatePayoutAPIError(t *testing.T) {
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error":"insufficient balance"}`))
+	}))
+	_, err := client.CreatePayout(context.Background(), gotropipay.PayoutRequest{
+		Beneficiary: gotropipay.DepositAccount{ID: 42}, Amount: gotropipay.NewMoney(2000, "EUR"), SecurityCode: "1",
+	})
+	if err == nil || !strings.Contains(err.Error(), "422") || !strings.Contains(err.Error(), "insufficient balance") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestGetAndCancelPayout(t *testing.T) {
+	states := map[string]string{"1": "pending", "2": "Completed"}
+	cancelled := map[string]bool{}
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/booking/"), "/")
+		state, ok := states[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if action == "cancel" {
+			if r.Method != "POST" {
+				http.Error(w, "method", http.StatusMethodNotAllowed)
+				return
+			}
+			cancelled[id] = true
+			state = "cancelled"
+		}
+		writeJSON(w, map[string]interface{}{"id": id, "state": state, "amount": 100, "currency": "EUR"})
+	}))
+	ctx := context.Background()
+
+	p, err := client.GetPayout(ctx, "2")
+	if err != nil || p.State != gotropipay.PayoutStateCompleted || !p.State.IsTerminal() {
+		t.Fatalf("GetPayout = %+v, %v", p, err)
+	}
+
+	p, err = client.CancelPayout(ctx, "1")
+	if err != nil || p.State != gotropipay.PayoutStateCancelled || !cancelled["1"] {
+		t.Errorf("CancelPayout(pending) = %+v, %v", p, err)
+	}
+
+	p, err = client.CancelPayout(ctx, "2")
+	if !errors.Is(err, gotropipay.ErrPayoutNotCancellable) || p == nil || p.State != gotropipay.PayoutStateCompleted || cancelled["2"] {
+		t.Errorf("CancelPayout(completed) = %+v, %v", p, err)
+	}
+
+	if _, err := client.GetPayout(ctx, "404"); err == nil {
+		t.Error("expected error for unknown payout")
+	}
+
+	// IDs are escaped, so one cannot reach another endpoint
+	var paths []string
+	escaped := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		writeJSON(w, map[string]interface{}{"id": "x", "state": "pending"})
+	}))
+	escaped.GetPayout(ctx, "3/cancel")
+	escaped.CancelPayout(ctx, "a b")
+	if want := []string{"/booking/3%2Fcancel", "/booking/a%20b", "/booking/a%20b/cancel"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %q, want %q", paths, want)
+	}
+}
@@ -0,0 +1,269 @@
+package qr
+
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y*c.Size+x] = dark
+	c.isFunction[y*c.Size+x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	// Timing patterns
+	for i := 0; i < c.Size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with separators
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	// Alignment patterns, except where they would overlap finders
+	pos := alignmentPositions(c.Version)
+	last := len(pos) - 1
+	for i := range pos {
+		for j := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(pos[i]+dx, pos[j]+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve format areas, then draw version information
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.set(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// alignmentPositions returns the row/column centres of alignment patterns
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, version*4+10; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+// drawFormatBits writes the level and mask, BCH-protected, in both copies
+func (c *Code) drawFormatBits(mask int) {
+	data := formatBits[c.Level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+
+	// Around the top-left finder
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	// Split between the other two finders
+	for i := 0; i < 8; i++ {
+		c.set(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i))
+	}
+	c.set(8, c.Size-8, true) // Always-dark module
+}
+
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	rem := c.Version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := c.Version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 != 0
+		a, b := c.Size-11+i%3, i/3
+		c.set(a, b, dark)
+		c.set(b, a, dark)
+	}
+}
+
+// drawCodewords places data in the zigzag order, two columns at a time from
+// the bottom-right corner, skipping function modules
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing column
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert // Upward
+				}
+				if c.isFunction[y*c.Size+x] || i >= len(data)*8 {
+					continue
+				}
+				c.modules[y*c.Size+x] = (data[i>>3]>>(7-i&7))&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask XORs the data modules with one of the eight mask patterns
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			idx := y*c.Size + x
+			if invert && !c.isFunction[idx] {
+				c.modules[idx] = !c.modules[idx]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol using the four rules of the standard; lower is
+// easier to scan
+func (c *Code) penalty() int {
+	result := 0
+	n := c.Size
+	at := func(x, y int) bool { return c.modules[y*n+x] }
+
+	// Rule 1 (runs of five or more) and rule 3 (finder-like patterns)
+	for pass := 0; pass < 2; pass++ {
+		for a := 0; a < n; a++ {
+			get := func(b int) bool {
+				if pass == 0 {
+					return at(b, a)
+				}
+				return at(a, b)
+			}
+			run := 0
+			var color bool
+			for b := 0; b < n; b++ {
+				if b == 0 || get(b) != color {
+					if run >= 5 {
+						result += 3 + run - 5
+					}
+					color, run = get(b), 1
+				} else {
+					run++
+				}
+			}
+			if run >= 5 {
+				result += 3 + run - 5
+			}
+
+			for b := 0; b+7 <= n; b++ {
+				if !finderLike(get, b) {
+					continue
+				}
+				lightBefore := lightRun(get, b-4, b, n)
+				lightAfter := lightRun(get, b+7, b+11, n)
+				if lightBefore || lightAfter {
+					result += 40
+				}
+			}
+		}
+	}
+
+	// Rule 2: 2x2 blocks of one color
+	for y := 0; y < n-1; y++ {
+		for x := 0; x < n-1; x++ {
+			v := at(x, y)
+			if v == at(x+1, y) && v == at(x, y+1) && v == at(x+1, y+1) {
+				result += 3
+			}
+		}
+	}
+
+	// Rule 4: balance of dark and light
+	dark := 0
+	for _, m := range c.modules {
+		if m {
+			dark++
+		}
+	}
+	total := n * n
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	if k > 0 {
+		result += k * 10
+	}
+	return result
+}
+
+// finderLike matches dark:light:dark*3:light:dark starting at b
+func finderLike(get func(int) bool, b int) bool {
+	pattern := [7]bool{true, false, true, true, true, false, true}
+	for i, want := range pattern {
+		if get(b+i) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// lightRun reports whether [from, to) is all light, treating the border as light
+func lightRun(get func(int) bool, from, to, n int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < n && get(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,201 @@
+// Package qr encodes QR codes (ISO/IEC 18004, model 2) without external
+// dependencies, and renders them as PNG, SVG or terminal text.
+package qr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Level is the error correction level, trading capacity for robustness
+type Level int
+
+const (
+	Low      Level = iota // Recovers ~7% damage
+	Medium                // ~15%
+	Quartile              // ~25%
+	High                  // ~30%
+)
+
+// formatBits are the 2-bit level identifiers used in the format information
+var formatBits = [4]int{1, 0, 3, 2}
+
+// ErrTooLong is returned when content does not fit in a version 40 symbol
+var ErrTooLong = errors.New("qr: content too long")
+
+// Code is an encoded QR symbol
+type Code struct {
+	Version int
+	Level   Level
+	Size    int // Modules per side, without quiet zone
+
+	modules    []bool // Row-major, true is dark
+	isFunction []bool // Finder, timing, alignment and format areas
+}
+
+// Dark reports whether the module at column x, row y is dark. Coordinates
+// outside the symbol (the quiet zone) are light.
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y*c.Size+x]
+}
+
+// Encode builds the smallest QR code holding content in byte mode at the
+// given error correction level
+func Encode(content string, level Level) (*Code, error) {
+	if level < Low || level > High {
+		return nil, fmt.Errorf("qr: invalid level %d", level)
+	}
+	data := []byte(content)
+
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if segmentBits(len(data), v) <= numDataCodewords(v, level)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	// Byte mode segment, terminator and padding
+	var bb bitBuffer
+	bb.append(0x4, 4)
+	bb.append(len(data), charCountBits(version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+	capacity := numDataCodewords(version, level) * 8
+	bb.append(0, min(4, capacity-bb.len()))
+	bb.append(0, (8-bb.len()%8)%8)
+	for pad := 0xEC; bb.len() < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	c := &Code{Version: version, Level: level, Size: version*4 + 17}
+	c.modules = make([]bool, c.Size*c.Size)
+	c.isFunction = make([]bool, c.Size*c.Size)
+	c.drawFunctionPatterns()
+	c.drawCodewords(addECCAndInterleave(bb.bytes(), version, level))
+
+	// Pick the mask with the lowest penalty
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask) // XOR again to undo
+	}
+	c.applyMask(best)
+	c.drawFormatBits(best)
+	c.isFunction = nil
+	return c, nil
+}
+
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+func segmentBits(n, version int) int {
+	if n >= 1<<charCountBits(version) {
+		return 1 << 30
+	}
+	return 4 + charCountBits(version) + n*8
+}
+
+// Capacity tables, indexed by level then version (index 0 unused)
+var eccCodewordsPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+var numErrorCorrectionBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// numRawDataModules counts the modules available for data and ECC bits
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func numDataCodewords(version int, level Level) int {
+	return numRawDataModules(version)/8 - eccCodewordsPerBlock[level][version]*numErrorCorrectionBlocks[level][version]
+}
+
+// addECCAndInterleave splits data into blocks, appends Reed-Solomon codewords
+// to each and interleaves them in transmission order
+func addECCAndInterleave(data []byte, version int, level Level) []byte {
+	numBlocks := numErrorCorrectionBlocks[level][version]
+	blockECCLen := eccCodewordsPerBlock[level][version]
+	rawCodewords := numRawDataModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(blockECCLen)
+	blocks := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		datLen := shortBlockLen - blockECCLen
+		if i >= numShortBlocks {
+			datLen++
+		}
+		dat := data[k : k+datLen]
+		k += datLen
+		block := append([]byte{}, dat...)
+		if i < numShortBlocks {
+			block = append(block, 0) // Placeholder so all blocks align
+		}
+		blocks[i] = append(block, reedSolomonRemainder(dat, divisor)...)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortBlockLen-blockECCLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// bitBuffer accumulates bits most significant first
+type bitBuffer []bool
+
+func (b *bitBuffer) append(val, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (val>>i)&1 != 0)
+	}
+}
+
+func (b bitBuffer) len() int { return len(b) }
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+	return out
+}
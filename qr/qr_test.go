@@ -0,0 +1,219 @@
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// Example from the thonky.com QR tutorial: "HELLO WORLD" at 1-M
+func TestReedSolomon(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	got := reedSolomonRemainder(data, reedSolomonDivisor(len(want)))
+	if !bytes.Equal(got, want) {
+		t.Errorf("ECC = %v, want %v", got, want)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	// Mask 0 for each level, from the standard's table
+	want := map[Level]string{Low: "111011111000100", Medium: "101010000010010", Quartile: "011010101011111", High: "001011010001001"}
+	for level, bits := range want {
+		c := newBlank(1, level)
+		c.drawFormatBits(0)
+		if got := readFormat(c); got != bits {
+			t.Errorf("level %d: format %s, want %s", level, got, bits)
+		}
+	}
+}
+
+// Every module not used by a function pattern must be available for data
+func TestFunctionPatternsLeaveRawModules(t *testing.T) {
+	for v := 1; v <= 40; v++ {
+		c := newBlank(v, Low)
+		free := 0
+		for _, f := range c.isFunction {
+			if !f {
+				free++
+			}
+		}
+		if free != numRawDataModules(v) {
+			t.Errorf("version %d: %d free modules, want %d", v, free, numRawDataModules(v))
+		}
+	}
+	if got := numRawDataModules(40) / 8; got != 3706 {
+		t.Errorf("version 40 has %d codewords, want 3706", got)
+	}
+}
+
+// Read the symbol back the way a scanner would and check every ECC block
+func TestEncodeRoundTrip(t *testing.T) {
+	inputs := []string{
+		"otpauth://totp/Tropipay:ops@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Tropipay",
+		strings.Repeat("bitcoin:bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq?amount=0.001&", 12),
+		"é",
+	}
+	for _, in := range inputs {
+		for level := Low; level <= High; level++ {
+			c, err := Encode(in, level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := decode(t, c); got != in {
+				t.Errorf("v%d level %d: decoded %q", c.Version, level, got)
+			}
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("x", 3000), Low); err != ErrTooLong {
+		t.Errorf("err = %v, want ErrTooLong", err)
+	}
+}
+
+func TestRenderers(t *testing.T) {
+	c, err := Encode("https://tppay.me/abc", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := c.PNG(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := img.Bounds().Dx(); w != (c.Size+8)*3 {
+		t.Errorf("PNG width %d", w)
+	}
+	if svg := c.SVG(4); !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `d="M4 4h7v1h-7z`) {
+		t.Errorf("SVG should start with the top-left finder row: %.200s", svg)
+	}
+	lines := strings.Split(strings.TrimRight(c.Terminal(false), "\n"), "\n")
+	if len(lines) != (c.Size+9)/2 {
+		t.Errorf("terminal output has %d lines", len(lines))
+	}
+}
+
+func newBlank(version int, level Level) *Code {
+	c := &Code{Version: version, Level: level, Size: version*4 + 17}
+	c.modules = make([]bool, c.Size*c.Size)
+	c.isFunction = make([]bool, c.Size*c.Size)
+	c.drawFunctionPatterns()
+	return c
+}
+
+// readFormat returns the first format copy, most significant bit first
+func readFormat(c *Code) string {
+	var coords [15][2]int
+	for i := 0; i <= 5; i++ {
+		coords[i] = [2]int{8, i}
+	}
+	coords[6], coords[7], coords[8] = [2]int{8, 7}, [2]int{8, 8}, [2]int{7, 8}
+	for i := 9; i < 15; i++ {
+		coords[i] = [2]int{14 - i, 8}
+	}
+	var sb strings.Builder
+	for i := 14; i >= 0; i-- {
+		if c.Dark(coords[i][0], coords[i][1]) {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
+
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+	ref := newBlank(c.Version, c.Level)
+
+	var format int
+	for _, b := range readFormat(c) {
+		format = format<<1 | int(b-'0')
+	}
+	format ^= 0x5412
+	if lvl := format >> 13; lvl != formatBits[c.Level] {
+		t.Fatalf("format level bits %d, want %d", lvl, formatBits[c.Level])
+	}
+	mask := (format >> 10) & 7
+
+	copy(ref.modules, c.modules)
+	ref.applyMask(mask)
+
+	// Zigzag read
+	var raw []byte
+	var cur byte
+	n := 0
+	for right := ref.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < ref.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = ref.Size - 1 - vert
+				}
+				if ref.isFunction[y*ref.Size+x] {
+					continue
+				}
+				cur <<= 1
+				if ref.modules[y*ref.Size+x] {
+					cur |= 1
+				}
+				if n++; n%8 == 0 {
+					raw = append(raw, cur)
+				}
+			}
+		}
+	}
+
+	// De-interleave and verify each block
+	numBlocks := numErrorCorrectionBlocks[c.Level][c.Version]
+	eccLen := eccCodewordsPerBlock[c.Level][c.Version]
+	total := numRawDataModules(c.Version) / 8
+	short := numBlocks - total%numBlocks
+	shortLen := total / numBlocks
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i < shortLen+1; i++ {
+		for j := 0; j < numBlocks; j++ {
+			if i == shortLen-eccLen && j < short {
+				continue
+			}
+			blocks[j] = append(blocks[j], raw[k])
+			k++
+		}
+	}
+	var data []byte
+	div := reedSolomonDivisor(eccLen)
+	for j, b := range blocks {
+		dat, ecc := b[:len(b)-eccLen], b[len(b)-eccLen:]
+		if !bytes.Equal(reedSolomonRemainder(dat, div), ecc) {
+			t.Fatalf("block %d fails ECC check", j)
+		}
+		data = append(data, dat...)
+	}
+
+	// Byte mode segment
+	bits := func(from, count int) int {
+		v := 0
+		for i := from; i < from+count; i++ {
+			v = v<<1 | int(data[i/8]>>(7-i%8)&1)
+		}
+		return v
+	}
+	if m := bits(0, 4); m != 4 {
+		t.Fatalf("mode %d, want byte mode", m)
+	}
+	ccBits := charCountBits(c.Version)
+	length := bits(4, ccBits)
+	out := make([]byte, length)
+	for i := range out {
+		out[i] = byte(bits(4+ccBits+8*i, 8))
+	}
+	return string(out)
+}
@@ -0,0 +1,94 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// QuietZone is the light border, in modules, added by every renderer
+const QuietZone = 4
+
+// Image renders the code with scale pixels per module
+func (c *Code) Image(scale int) image.Image {
+	if scale < 1 {
+		scale = 1
+	}
+	size := (c.Size + 2*QuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			if c.Dark(px/scale-QuietZone, py/scale-QuietZone) {
+				img.SetColorIndex(px, py, 1)
+			}
+		}
+	}
+	return img
+}
+
+// PNG encodes the code as a PNG with scale pixels per module
+func (c *Code) PNG(scale int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(scale)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the code as a standalone SVG document, scale user units per
+// module. Runs of dark modules in a row are merged into one path segment.
+func (c *Code) SVG(scale int) string {
+	if scale < 1 {
+		scale = 1
+	}
+	dim := c.Size + 2*QuietZone
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; {
+			if !c.Dark(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < c.Size && c.Dark(x, y) {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start+QuietZone, y+QuietZone, x-start, x-start)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		dim*scale, dim*scale, dim, dim)
+	sb.WriteString(`<rect width="100%" height="100%" fill="#fff"/>`)
+	fmt.Fprintf(&sb, `<path fill="#000" d="%s"/></svg>`, path.String())
+	return sb.String()
+}
+
+// Terminal renders the code with Unicode half blocks, two rows per line.
+// Blocks are drawn for dark modules, which suits dark-on-light terminals; set
+// invert for the usual light-on-dark terminal so the code reads correctly.
+func (c *Code) Terminal(invert bool) string {
+	var sb strings.Builder
+	dark := func(x, y int) bool { return c.Dark(x, y) != invert }
+	for y := -QuietZone; y < c.Size+QuietZone; y += 2 {
+		for x := -QuietZone; x < c.Size+QuietZone; x++ {
+			top, bottom := dark(x, y), y+1 < c.Size+QuietZone && dark(x, y+1)
+			switch {
+			case top && bottom:
+				sb.WriteRune('█')
+			case top:
+				sb.WriteRune('▀')
+			case bottom:
+				sb.WriteRune('▄')
+			default:
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,133 @@
+package gotropipay
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tropipay/gotropipay/qr"
+)
+
+// ErrCryptoAmountUnsupported is returned by CryptoAddress.URI when an amount is
+// requested for a network or token with no standard way to encode it
+var ErrCryptoAmountUnsupported = errors.New("amount cannot be encoded for this network")
+
+// QRCode encodes the paylink's ShortURL (or PaymentURL) locally, for displays
+// where QRImage is empty or a network fetch is not an option
+func (p PaymentCard) QRCode() (*qr.Code, error) {
+	link := p.ShortURL
+	if link == "" {
+		link = p.PaymentURL
+	}
+	if link == "" {
+		return nil, errors.New("payment card has no URL to encode")
+	}
+	return qr.Encode(link, qr.Medium)
+}
+
+// cryptoNetwork describes the payment URI of a chain's native coin
+type cryptoNetwork struct {
+	scheme  string
+	coin    string // Native currency; other currencies on the chain are tokens
+	param   string // Query parameter carrying the amount
+	weiUnit int    // >0 for EIP-681, whose amount is an integer in 10^-weiUnit units
+	chainID string // EIP-681 chain suffix for non-mainnet EVM chains
+}
+
+// cryptoNetworks is keyed by upper-case network name or ticker
+var cryptoNetworks = map[string]cryptoNetwork{
+	"BTC":      {scheme: "bitcoin", coin: "BTC", param: "amount"}, // BIP 21
+	"BITCOIN":  {scheme: "bitcoin", coin: "BTC", param: "amount"},
+	"LTC":      {scheme: "litecoin", coin: "LTC", param: "amount"},
+	"LITECOIN": {scheme: "litecoin", coin: "LTC", param: "amount"},
+	"BCH":      {scheme: "bitcoincash", coin: "BCH", param: "amount"},
+	"DOGE":     {scheme: "dogecoin", coin: "DOGE", param: "amount"},
+	"ETH":      {scheme: "ethereum", coin: "ETH", param: "value", weiUnit: 18}, // EIP-681
+	"ETHEREUM": {scheme: "ethereum", coin: "ETH", param: "value", weiUnit: 18},
+	"ERC20":    {scheme: "ethereum", coin: "ETH", param: "value", weiUnit: 18},
+	"BSC":      {scheme: "ethereum", coin: "BNB", param: "value", weiUnit: 18, chainID: "56"},
+	"BEP20":    {scheme: "ethereum", coin: "BNB", param: "value", weiUnit: 18, chainID: "56"},
+	"SOL":      {scheme: "solana", coin: "SOL", param: "amount"}, // Solana Pay
+	"SOLANA":   {scheme: "solana", coin: "SOL", param: "amount"},
+}
+
+// URI returns a wallet payment URI for the address, such as
+// "bitcoin:bc1...?amount=0.0015". amount is a decimal in whole coins ("" for
+// none). Tokens (e.g. USDT on TRON or ERC20) and unknown networks have no
+// widely supported URI, so the bare address is returned and an amount is
+// rejected with ErrCryptoAmountUnsupported.
+func (a CryptoAddress) URI(amount string) (string, error) {
+	if a.Address == "" {
+		return "", errors.New("crypto address is empty")
+	}
+	if amount != "" && !isDecimal(amount) {
+		return "", fmt.Errorf("invalid amount %q", amount)
+	}
+
+	// Only fall back to the currency when no network is given: an unknown
+	// network (e.g. ARBITRUM for ETH) must never produce another chain's URI
+	key := a.Network
+	if key == "" {
+		key = a.Currency
+	}
+	network, ok := cryptoNetworks[strings.ToUpper(key)]
+	if !ok || (a.Currency != "" && !strings.EqualFold(a.Currency, network.coin)) {
+		if amount != "" {
+			return "", fmt.Errorf("%s on %s: %w", a.Currency, a.Network, ErrCryptoAmountUnsupported)
+		}
+		return a.Address, nil
+	}
+
+	// Bitcoin Cash addresses often carry their own prefix
+	uri := network.scheme + ":" + strings.TrimPrefix(a.Address, network.scheme+":")
+	if network.chainID != "" {
+		uri += "@" + network.chainID
+	}
+	if amount == "" {
+		return uri, nil
+	}
+	if network.weiUnit > 0 {
+		scaled, ok := scaleDecimal(amount, network.weiUnit)
+		if !ok {
+			return "", fmt.Errorf("amount %q has more than %d decimals", amount, network.weiUnit)
+		}
+		amount = scaled
+	}
+	return uri + "?" + url.Values{network.param: {amount}}.Encode(), nil
+}
+
+// QRCode encodes URI(amount) as a QR code
+func (a CryptoAddress) QRCode(amount string) (*qr.Code, error) {
+	uri, err := a.URI(amount)
+	if err != nil {
+		return nil, err
+	}
+	return qr.Encode(uri, qr.Medium)
+}
+
+func isDecimal(s string) bool {
+	whole, frac, hasDot := strings.Cut(s, ".")
+	if whole == "" || (hasDot && frac == "") {
+		return false
+	}
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// scaleDecimal converts "1.5" with exp 18 into "1500000000000000000"
+func scaleDecimal(s string, exp int) (string, bool) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > exp {
+		return "", false
+	}
+	digits := strings.TrimLeft(whole+frac+strings.Repeat("0", exp-len(frac)), "0")
+	if digits == "" {
+		digits = "0"
+	}
+	return digits, true
+}
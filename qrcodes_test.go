@@ -0,0 +1,49 @@
+package gotropipay_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestCryptoAddressURI(t *testing.T) {
+	cases := []struct {
+		addr   gotropipay.CryptoAddress
+		amount string
+		want   string
+	}{
+		{gotropipay.CryptoAddress{Address: "bc1qxyz", Network: "BTC", Currency: "BTC"}, "0.0015", "bitcoin:bc1qxyz?amount=0.0015"},
+		{gotropipay.CryptoAddress{Address: "0xAbC", Network: "ERC20", Currency: "ETH"}, "1.5", "ethereum:0xAbC?value=1500000000000000000"},
+		{gotropipay.CryptoAddress{Address: "0xAbC", Network: "BEP20", Currency: "BNB"}, "", "ethereum:0xAbC@56"},
+		{gotropipay.CryptoAddress{Address: "bitcoincash:qq1", Network: "BCH", Currency: "BCH"}, "", "bitcoincash:qq1"},
+		{gotropipay.CryptoAddress{Address: "TXyz", Network: "TRC20", Currency: "USDT"}, "", "TXyz"},
+		{gotropipay.CryptoAddress{Address: "0xabc", Network: "ARBITRUM", Currency: "ETH"}, "", "0xabc"},
+		{gotropipay.CryptoAddress{Address: "TXyz", Network: "TRC20", Currency: "BTC"}, "", "TXyz"},
+		{gotropipay.CryptoAddress{Address: "bc1qxyz", Currency: "BTC"}, "0.1", "bitcoin:bc1qxyz?amount=0.1"},
+	}
+	for _, c := range cases {
+		got, err := c.addr.URI(c.amount)
+		if err != nil || got != c.want {
+			t.Errorf("URI(%+v, %q) = %q, %v; want %q", c.addr, c.amount, got, err, c.want)
+		}
+	}
+
+	for _, a := range []gotropipay.CryptoAddress{
+		{Address: "0xAbC", Network: "ERC20", Currency: "USDT"},
+		{Address: "0xabc", Network: "ARBITRUM", Currency: "ETH"},
+		{Address: "TXyz", Network: "TRC20", Currency: "BTC"},
+	} {
+		if _, err := a.URI("1"); !errors.Is(err, gotropipay.ErrCryptoAmountUnsupported) {
+			t.Errorf("%+v: err = %v, want ErrCryptoAmountUnsupported", a, err)
+		}
+	}
+	if _, err := cases[0].addr.URI("1e3"); err == nil {
+		t.Error("expected error for non-decimal amount")
+	}
+
+	card := gotropipay.PaymentCard{ShortURL: "https://tppay.me/abc123"}
+	if code, err := card.QRCode(); err != nil || code.Size == 0 {
+		t.Errorf("paylink QR: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package gotropipay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRateCacheTTL is how long exchange rates are reused before refetching
+const DefaultRateCacheTTL = time.Minute
+
+// ExchangeRate is the price of one unit of From expressed in To
+type ExchangeRate struct {
+	From      string
+	To        string
+	Rate      float64
+	FetchedAt time.Time
+}
+
+// Convert applies the rate to m, rounding half away from zero to the nearest cent
+func (r ExchangeRate) Convert(m Money) (Money, error) {
+	if m.Currency != "" && !strings.EqualFold(m.Currency, r.From) {
+		return Money{}, fmt.Errorf("cannot convert %s with a %s/%s rate", m.Currency, r.From, r.To)
+	}
+	return NewMoney(int64(math.Round(float64(m.Amount)*r.Rate)), r.To), nil
+}
+
+// Inverse returns the rate for the opposite direction
+func (r ExchangeRate) Inverse() ExchangeRate {
+	inv := r
+	inv.From, inv.To = r.To, r.From
+	if r.Rate != 0 {
+		inv.Rate = 1 / r.Rate
+	}
+	return inv
+}
+
+// ConversionQuote is an amount priced in another currency
+type ConversionQuote struct {
+	Source    Money
+	Target    Money
+	Rate      ExchangeRate
+	ExpiresAt time.Time // When the cached rate behind the quote goes stale
+}
+
+// GetExchangeRate returns the current rate from one currency to another.
+// Rates are cached in memory for the duration set by WithRateCacheTTL.
+func (c *Client) GetExchangeRate(ctx context.Context, from, to string) (*ExchangeRate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return &ExchangeRate{From: from, To: to, Rate: 1, FetchedAt: time.Now()}, nil
+	}
+
+	if rate, ok := c.rates.get(from, to, c.rateTTL); ok {
+		return &rate, nil
+	}
+
+	req := struct {
+		CurrencyFrom string `json:"currencyFrom"`
+		CurrencyTo   string `json:"currencyTo"`
+	}{from, to}
+
+	// The endpoint answers with a bare number; some deployments wrap it in {"rate": n}
+	var raw json.RawMessage
+	if err := c.Request(ctx, "POST", "/currency/rate", req, &raw); err != nil {
+		return nil, err
+	}
+	value, err := parseRate(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := ExchangeRate{From: from, To: to, Rate: value, FetchedAt: time.Now()}
+	c.rates.put(rate)
+	return &rate, nil
+}
+
+// QuoteConversion prices amount in the target currency
+func (c *Client) QuoteConversion(ctx context.Context, amount Money, to string) (*ConversionQuote, error) {
+	if amount.Currency == "" {
+		return nil, fmt.Errorf("amount has no currency")
+	}
+	rate, err := c.GetExchangeRate(ctx, amount.Currency, to)
+	if err != nil {
+		return nil, err
+	}
+	target, err := rate.Convert(amount)
+	if err != nil {
+		return nil, err
+	}
+	return &ConversionQuote{
+		Source:    amount,
+		Target:    target,
+		Rate:      *rate,
+		ExpiresAt: rate.FetchedAt.Add(c.rateTTL),
+	}, nil
+}
+
+func parseRate(raw json.RawMessage) (float64, error) {
+	var value float64
+	if err := json.Unmarshal(raw, &value); err != nil {
+		var wrapped struct {
+			Rate float64 `json:"rate"`
+		}
+		if err := json.Unmarshal(raw, &wrapped); err != nil {
+			return 0, fmt.Errorf("unexpected rate response: %s", string(raw))
+		}
+		value = wrapped.Rate
+	}
+	if value <= 0 {
+		return 0, fmt.Errorf("invalid exchange rate %v", value)
+	}
+	return value, nil
+}
+
+// rateCache holds recently fetched rates keyed by "FROM/TO"
+type rateCache struct {
+	mu    sync.Mutex
+	rates map[string]ExchangeRate
+}
+
+func (rc *rateCache) get(from, to string, ttl time.Duration) (ExchangeRate, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rate, ok := rc.rates[from+"/"+to]
+	if !ok || time.Since(rate.FetchedAt) >= ttl {
+		return ExchangeRate{}, false
+	}
+	return rate, true
+}
+
+func (rc *rateCache) put(rate ExchangeRate) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.rates == nil {
+		rc.rates = make(map[string]ExchangeRate)
+	}
+	rc.rates[rate.From+"/"+rate.To] = rate
+}
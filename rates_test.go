@@ -0,0 +1,58 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestQuoteConversionCachesRate(t *testing.T) {
+	var calls atomic.Int32
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/currency/rate" {
+			http.NotFound(w, r)
+			return
+		}
+		calls.Add(1)
+		var req struct{ CurrencyFrom, CurrencyTo string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.CurrencyFrom != "EUR" || req.CurrencyTo != "USD" {
+			t.Errorf("unexpected pair %s/%s", req.CurrencyFrom, req.CurrencyTo)
+		}
+		writeJSON(w, 1.0825)
+	}))
+	ctx := context.Background()
+
+	q, err := client.QuoteConversion(ctx, gotropipay.NewMoney(1500, "EUR"), "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Target != gotropipay.NewMoney(1624, "USD") {
+		t.Errorf("target = %v, want 16.24 USD", q.Target)
+	}
+
+	if _, err := client.GetExchangeRate(ctx, "eur", "usd"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetExchangeRate(ctx, "EUR", "EUR"); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("rate endpoint called %d times, want 1", n)
+	}
+
+	back, err := q.Rate.Inverse().Convert(q.Target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Currency != "EUR" || back.Amount != 1500 {
+		t.Errorf("inverse = %v, want 15.00 EUR", back)
+	}
+	if _, err := q.Rate.Convert(gotropipay.NewMoney(100, "GBP")); err == nil {
+		t.Error("expected error converting GBP with an EUR rate")
+	}
+}
@@ -0,0 +1,192 @@
+// Package reconcile matches paylinks against the movements that paid them.
+package reconcile
+
+import (
+	"context"
+	"iter"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// Source provides both sides of the reconciliation. *gotropipay.Client implements it.
+type Source interface {
+	ListPaymentCards(ctx context.Context) ([]gotropipay.PaymentCard, error)
+	AllMovements(ctx context.Context, filter *gotropipay.MovementFilter) iter.Seq2[gotropipay.Movement, error]
+}
+
+// Options configures a reconciliation run. The range selects movements;
+// they are matched against every paylink, since a paylink created before the
+// range may be paid within it. Only paylinks created in the range are
+// reported as unpaid.
+type Options struct {
+	From time.Time // Inclusive lower bound on createdAt
+	To   time.Time // Exclusive upper bound on createdAt
+
+	// AmountTolerance is the absolute difference (in cents) accepted between
+	// the paylink amount and the received amount, e.g. to absorb fixed fees
+	AmountTolerance int64
+	// TolerancePercent is an additional relative tolerance (3.5 = 3.5%)
+	TolerancePercent float64
+
+	// PaidStates lists the movement states that count as paid (default: completed)
+	PaidStates []string
+}
+
+// Match pairs a paylink with the movement that paid it
+type Match struct {
+	Card       gotropipay.PaymentCard `json:"card"`
+	Movement   gotropipay.Movement    `json:"movement"`
+	Difference int64                  `json:"difference"` // Card amount minus received amount
+}
+
+// Duplicate groups records sharing a reference that should be unique
+type Duplicate struct {
+	Reference string                   `json:"reference"`
+	Cards     []gotropipay.PaymentCard `json:"cards,omitempty"`
+	Movements []gotropipay.Movement    `json:"movements,omitempty"`
+}
+
+// Report is the outcome of a reconciliation run
+type Report struct {
+	From             time.Time                `json:"from"`
+	To               time.Time                `json:"to"`
+	Matched          []Match                  `json:"matched"`
+	UnknownMovements []gotropipay.Movement    `json:"unknownMovements"` // Paid, but no paylink has the reference
+	Unpaid           []gotropipay.PaymentCard `json:"unpaid"`           // Paylinks without a paid movement
+	AmountMismatches []Match                  `json:"amountMismatches"` // Amount or currency outside tolerance
+	Duplicates       []Duplicate              `json:"duplicates"`
+}
+
+// Run pulls every paylink and the movements of the configured range and reconciles them
+func Run(ctx context.Context, src Source, opts Options) (*Report, error) {
+	cards, err := src.ListPaymentCards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &gotropipay.MovementFilter{}
+	if !opts.From.IsZero() {
+		filter.CreatedAtFrom = opts.From.Format(time.RFC3339)
+	}
+	if !opts.To.IsZero() {
+		filter.CreatedAtTo = opts.To.Format(time.RFC3339)
+	}
+
+	var movements []gotropipay.Movement
+	for m, err := range src.AllMovements(ctx, filter) {
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+
+	report := Reconcile(cards, movements, opts)
+	// Older paylinks were likely paid, or reported unpaid, in an earlier range
+	var unpaid []gotropipay.PaymentCard
+	for _, card := range report.Unpaid {
+		if withinRange(card.CreatedAt, opts.From, opts.To) {
+			unpaid = append(unpaid, card)
+		}
+	}
+	report.Unpaid = unpaid
+	report.From = opts.From
+	report.To = opts.To
+	return report, nil
+}
+
+// Reconcile matches already-fetched paylinks and movements by reference, amount and currency
+func Reconcile(cards []gotropipay.PaymentCard, movements []gotropipay.Movement, opts Options) *Report {
+	paid := opts.PaidStates
+	if len(paid) == 0 {
+		paid = []string{string(gotropipay.MovementStateCompleted)}
+	}
+
+	report := &Report{}
+
+	cardsByRef := make(map[string][]gotropipay.PaymentCard)
+	var refs []string
+	for _, card := range cards {
+		if _, ok := cardsByRef[card.Reference]; !ok {
+			refs = append(refs, card.Reference)
+		}
+		cardsByRef[card.Reference] = append(cardsByRef[card.Reference], card)
+	}
+
+	movsByRef := make(map[string][]gotropipay.Movement)
+	for _, m := range movements {
+		if !isPaid(m.State, paid) {
+			continue
+		}
+		if _, ok := cardsByRef[m.Reference]; !ok || m.Reference == "" {
+			report.UnknownMovements = append(report.UnknownMovements, m)
+			continue
+		}
+		movsByRef[m.Reference] = append(movsByRef[m.Reference], m)
+	}
+
+	sort.Strings(refs)
+	for _, ref := range refs {
+		refCards := cardsByRef[ref]
+		refMovs := movsByRef[ref]
+
+		if len(refCards) > 1 || len(refMovs) > 1 {
+			report.Duplicates = append(report.Duplicates, Duplicate{Reference: ref, Cards: refCards, Movements: refMovs})
+		}
+
+		card := refCards[0]
+		if len(refMovs) == 0 {
+			report.Unpaid = append(report.Unpaid, refCards...)
+			continue
+		}
+
+		m := Match{Card: card, Movement: refMovs[0], Difference: card.Amount - refMovs[0].Amount}
+		if withinTolerance(card, refMovs[0], opts) {
+			report.Matched = append(report.Matched, m)
+		} else {
+			report.AmountMismatches = append(report.AmountMismatches, m)
+		}
+	}
+
+	return report
+}
+
+func isPaid(state string, paid []string) bool {
+	for _, s := range paid {
+		if strings.EqualFold(state, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func withinTolerance(card gotropipay.PaymentCard, m gotropipay.Movement, opts Options) bool {
+	if !strings.EqualFold(card.Currency, m.Currency) {
+		return false
+	}
+	diff := card.Amount - m.Amount
+	if diff < 0 {
+		diff = -diff
+	}
+	allowed := opts.AmountTolerance + int64(float64(card.Amount)*opts.TolerancePercent/100)
+	return diff <= allowed
+}
+
+func withinRange(createdAt string, from, to time.Time) bool {
+	if from.IsZero() && to.IsZero() {
+		return true
+	}
+	t, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return false
+	}
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !t.Before(to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package reconcile_test
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+	"github.com/tropipay/gotropipay/reconcile"
+)
+
+func TestReconcile(t *testing.T) {
+	cards := []gotropipay.PaymentCard{
+		{ID: "a", Reference: "A", Amount: 1000, Currency: "EUR"},
+		{ID: "b", Reference: "B", Amount: 2000, Currency: "EUR"},
+		{ID: "c", Reference: "C", Amount: 3000, Currency: "EUR"},
+		{ID: "d", Reference: "D", Amount: 4000, Currency: "EUR"},
+	}
+	movements := []gotropipay.Movement{
+		{ID: 1, Reference: "A", Amount: 965, Currency: "EUR", State: "completed"}, // 3.5% fee
+		{ID: 2, Reference: "B", Amount: 1500, Currency: "EUR", State: "completed"},
+		{ID: 3, Reference: "D", Amount: 4000, Currency: "EUR", State: "completed"},
+		{ID: 4, Reference: "D", Amount: 4000, Currency: "EUR", State: "completed"},
+		{ID: 5, Reference: "X", Amount: 100, Currency: "EUR", State: "completed"},
+		{ID: 6, Reference: "C", Amount: 3000, Currency: "EUR", State: "pending"},
+	}
+
+	r := reconcile.Reconcile(cards, movements, reconcile.Options{TolerancePercent: 3.5})
+
+	if len(r.Matched) != 2 || r.Matched[0].Card.ID != "a" || r.Matched[1].Card.ID != "d" {
+		t.Errorf("matched: %+v", r.Matched)
+	}
+	if len(r.AmountMismatches) != 1 || r.AmountMismatches[0].Difference != 500 {
+		t.Errorf("mismatches: %+v", r.AmountMismatches)
+	}
+	if len(r.Unpaid) != 1 || r.Unpaid[0].ID != "c" {
+		t.Errorf("unpaid: %+v", r.Unpaid)
+	}
+	if len(r.UnknownMovements) != 1 || r.UnknownMovements[0].Reference != "X" {
+		t.Errorf("unknown: %+v", r.UnknownMovements)
+	}
+	if len(r.Duplicates) != 1 || len(r.Duplicates[0].Movements) != 2 {
+		t.Errorf("duplicates: %+v", r.Duplicates)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "amount_mismatch,B,b,2,2000,1500,EUR,500") {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
+
+type fakeSource struct {
+	cards     []gotropipay.PaymentCard
+	movements []gotropipay.Movement
+	filter    *gotropipay.MovementFilter
+}
+
+func (f *fakeSource) ListPaymentCards(ctx context.Context) ([]gotropipay.PaymentCard, error) {
+	return f.cards, nil
+}
+
+func (f *fakeSource) AllMovements(ctx context.Context, filter *gotropipay.MovementFilter) iter.Seq2[gotropipay.Movement, error] {
+	f.filter = filter
+	return func(yield func(gotropipay.Movement, error) bool) {
+		for _, m := range f.movements {
+			if m.CreatedAt < filter.CreatedAtFrom || (filter.CreatedAtTo != "" && m.CreatedAt >= filter.CreatedAtTo) {
+				continue
+			}
+			if !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestRunMatchesPaylinksFromBeforeTheRange(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	src := &fakeSource{
+		cards: []gotropipay.PaymentCard{
+			{ID: "old-paid", Reference: "OLD", Amount: 1000, Currency: "EUR", CreatedAt: "2026-09-28T10:00:00Z"},
+			{ID: "old-open", Reference: "STALE", Amount: 1000, Currency: "EUR", CreatedAt: "2026-09-01T10:00:00Z"},
+			{ID: "new-open", Reference: "NEW", Amount: 2000, Currency: "EUR", CreatedAt: "2026-10-05T10:00:00Z"},
+		},
+		movements: []gotropipay.Movement{
+			// Paid in October for a September paylink
+			{ID: 1, Reference: "OLD", Amount: 1000, Currency: "EUR", State: "completed", CreatedAt: "2026-10-02T09:00:00Z"},
+			{ID: 2, Reference: "NEW", Amount: 2000, Currency: "EUR", State: "completed", CreatedAt: "2026-11-02T09:00:00Z"},
+		},
+	}
+
+	r, err := reconcile.Run(context.Background(), src, reconcile.Options{From: from, To: to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Matched) != 1 || r.Matched[0].Card.ID != "old-paid" || len(r.UnknownMovements) != 0 {
+		t.Errorf("matched %+v, unknown %+v", r.Matched, r.UnknownMovements)
+	}
+	if len(r.Unpaid) != 1 || r.Unpaid[0].ID != "new-open" {
+		t.Errorf("unpaid: %+v", r.Unpaid)
+	}
+	if src.filter.CreatedAtFrom != "2026-10-01T00:00:00Z" || src.filter.CreatedAtTo != "2026-11-01T00:00:00Z" {
+		t.Errorf("movement filter = %+v", src.filter)
+	}
+}
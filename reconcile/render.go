@@ -0,0 +1,93 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// Row statuses used in the CSV output
+const (
+	StatusMatched         = "matched"
+	StatusUnknownMovement = "unknown_movement"
+	StatusUnpaid          = "unpaid"
+	StatusAmountMismatch  = "amount_mismatch"
+	StatusDuplicate       = "duplicate"
+)
+
+var csvHeader = []string{"status", "reference", "paylink_id", "movement_id", "expected_amount", "received_amount", "currency", "difference"}
+
+// WriteJSON renders the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV renders the report as one CSV row per finding
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, m := range r.Matched {
+		if err := cw.Write(matchRow(StatusMatched, m)); err != nil {
+			return err
+		}
+	}
+	for _, m := range r.AmountMismatches {
+		if err := cw.Write(matchRow(StatusAmountMismatch, m)); err != nil {
+			return err
+		}
+	}
+	for _, c := range r.Unpaid {
+		if err := cw.Write([]string{StatusUnpaid, c.Reference, c.ID, "", itoa(c.Amount), "", c.Currency, ""}); err != nil {
+			return err
+		}
+	}
+	for _, m := range r.UnknownMovements {
+		if err := cw.Write(movementRow(StatusUnknownMovement, m)); err != nil {
+			return err
+		}
+	}
+	for _, d := range r.Duplicates {
+		for _, c := range d.Cards {
+			if err := cw.Write([]string{StatusDuplicate, d.Reference, c.ID, "", itoa(c.Amount), "", c.Currency, ""}); err != nil {
+				return err
+			}
+		}
+		for _, m := range d.Movements {
+			if err := cw.Write(movementRow(StatusDuplicate, m)); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func matchRow(status string, m Match) []string {
+	return []string{
+		status,
+		m.Card.Reference,
+		m.Card.ID,
+		m.Movement.IDString(),
+		itoa(m.Card.Amount),
+		itoa(m.Movement.Amount),
+		m.Card.Currency,
+		itoa(m.Difference),
+	}
+}
+
+func movementRow(status string, m gotropipay.Movement) []string {
+	return []string{status, m.Reference, "", m.IDString(), "", itoa(m.Amount), m.Currency, ""}
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,211 @@
+package gotropipay
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrMovementNotRefundable is returned for movements that are not completed
+	ErrMovementNotRefundable = errors.New("movement is not completed and cannot be refunded")
+	// ErrRefundExceedsRemaining is returned when a refund is larger than what is left to refund
+	ErrRefundExceedsRemaining = errors.New("refund exceeds refundable amount")
+)
+
+// Refund is a full or partial return of a completed payment
+type Refund struct {
+	ID         string
+	MovementID string
+	Reference  string
+	Amount     Money
+	Reason     string
+	State      MovementState // pending until the card network settles it
+	CreatedAt  string
+	UpdatedAt  string
+}
+
+// RefundRequest is the explicit form of RefundMovement. CreateRefund fills
+// in Amount and IdempotencyKey before sending, so passing the same request
+// again after a failure retries that refund instead of issuing a new one.
+type RefundRequest struct {
+	MovementID     string
+	Amount         Money // Zero refunds everything still refundable
+	Reason         string
+	IdempotencyKey string // Generated when empty; set it to retry an earlier attempt
+}
+
+// RefundError is returned when a refund request fails after its key was
+// assigned. The refund may have gone through; retry with the same key and
+// amount to find out without refunding twice.
+type RefundError struct {
+	MovementID     string
+	Amount         Money
+	IdempotencyKey string
+	Err            error
+}
+
+func (e *RefundError) Error() string {
+	return fmt.Sprintf("refund of %s on movement %s (key %s): %v", e.Amount, e.MovementID, e.IdempotencyKey, e.Err)
+}
+
+func (e *RefundError) Unwrap() error { return e.Err }
+
+// refundResponse is the wire format of refund endpoints
+type refundResponse struct {
+	ID         interface{} `json:"id"`
+	MovementID interface{} `json:"movementId"`
+	Reference  string      `json:"reference"`
+	Amount     int64       `json:"amount"`
+	Currency   string      `json:"currency"`
+	Reason     string      `json:"reason"`
+	State      string      `json:"state"`
+	CreatedAt  string      `json:"createdAt"`
+	UpdatedAt  string      `json:"updatedAt"`
+}
+
+func (r refundResponse) refund() Refund {
+	return Refund{
+		ID:         Movement{ID: r.ID}.IDString(),
+		MovementID: Movement{ID: r.MovementID}.IDString(),
+		Reference:  r.Reference,
+		Amount:     NewMoney(r.Amount, r.Currency),
+		Reason:     r.Reason,
+		State:      MovementState(strings.ToLower(r.State)),
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+// RefundableRemaining is the movement amount minus refunds that have not
+// failed or been cancelled. Refunds in other currencies are ignored.
+func (m Movement) RefundableRemaining(refunds []Refund) Money {
+	remaining := NewMoney(m.Amount, m.Currency)
+	for _, r := range refunds {
+		if r.State == MovementStateFailed || r.State == MovementStateCancelled {
+			continue
+		}
+		if next, err := remaining.Sub(r.Amount); err == nil {
+			remaining = next
+		}
+	}
+	if remaining.Amount < 0 {
+		remaining.Amount = 0
+	}
+	return remaining
+}
+
+// RefundMovement refunds a completed payment. A zero amount refunds whatever
+// is still refundable. Calling it again issues another refund; to retry a
+// failed call, pass the *RefundError's amount and key to CreateRefund.
+func (c *Client) RefundMovement(ctx context.Context, movementID string, amount Money, reason string) (*Refund, error) {
+	return c.CreateRefund(ctx, &RefundRequest{MovementID: movementID, Amount: amount, Reason: reason})
+}
+
+// CreateRefund refunds a completed payment. A new request (no key) is first
+// checked against what is still refundable, and its amount and key are then
+// recorded in req. A request that already has a key is a retry: it is sent
+// as is and the server, which deduplicates by key, enforces the limit.
+func (c *Client) CreateRefund(ctx context.Context, req *RefundRequest) (*Refund, error) {
+	if req.IdempotencyKey == "" {
+		amount, err := c.refundableAmount(ctx, req.MovementID, req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return nil, err
+		}
+		req.Amount, req.IdempotencyKey = amount, key
+	} else if req.Amount.Amount <= 0 || req.Amount.Currency == "" {
+		return nil, errors.New("refund retry requires the amount of the original attempt")
+	}
+
+	payload := struct {
+		Amount   int64  `json:"amount"`
+		Currency string `json:"currency"`
+		Reason   string `json:"reason,omitempty"`
+	}{req.Amount.Amount, req.Amount.Currency, req.Reason}
+
+	var resp refundResponse
+	path := "/movements/" + url.PathEscape(req.MovementID) + "/refund"
+	err := c.requestWithHeaders(ctx, "POST", path, http.Header{"Idempotency-Key": {req.IdempotencyKey}}, payload, &resp)
+	if err != nil {
+		return nil, &RefundError{MovementID: req.MovementID, Amount: req.Amount, IdempotencyKey: req.IdempotencyKey, Err: err}
+	}
+	refund := resp.refund()
+	if refund.MovementID == "" {
+		refund.MovementID = req.MovementID
+	}
+	return &refund, nil
+}
+
+// refundableAmount resolves the amount of a new refund, checking it against
+// the movement and the refunds already issued
+func (c *Client) refundableAmount(ctx context.Context, movementID string, amount Money) (Money, error) {
+	mov, err := c.GetMovement(ctx, movementID)
+	if err != nil {
+		return Money{}, err
+	}
+	if MovementState(strings.ToLower(mov.State)) != MovementStateCompleted {
+		return Money{}, fmt.Errorf("%w: state %s", ErrMovementNotRefundable, mov.State)
+	}
+	prior, err := c.ListRefunds(ctx, movementID)
+	if err != nil {
+		return Money{}, err
+	}
+	remaining := mov.RefundableRemaining(prior)
+
+	if amount.IsZero() {
+		amount = remaining
+	}
+	if amount.Currency == "" {
+		amount.Currency = remaining.Currency
+	}
+	if !strings.EqualFold(amount.Currency, remaining.Currency) {
+		return Money{}, fmt.Errorf("refund currency %s does not match payment currency %s", amount.Currency, remaining.Currency)
+	}
+	if amount.Amount <= 0 || amount.Amount > remaining.Amount {
+		return Money{}, fmt.Errorf("%w: requested %s, remaining %s", ErrRefundExceedsRemaining, amount, remaining)
+	}
+	return amount, nil
+}
+
+// newIdempotencyKey returns a random key for requests without a natural one
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// ListRefunds returns the refunds issued against a movement
+func (c *Client) ListRefunds(ctx context.Context, movementID string) ([]Refund, error) {
+	var resp []refundResponse
+	err := c.Request(ctx, "GET", "/movements/"+url.PathEscape(movementID)+"/refunds", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	refunds := make([]Refund, len(resp))
+	for i, r := range resp {
+		refunds[i] = r.refund()
+	}
+	return refunds, nil
+}
+
+// GetRefund retrieves the current status of a refund
+func (c *Client) GetRefund(ctx context.Context, id string) (*Refund, error) {
+	var resp refundResponse
+	err := c.Request(ctx, "GET", "/refunds/"+url.PathEscape(id), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	refund := resp.refund()
+	return &refund, nil
+}
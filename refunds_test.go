@@ -0,0 +1,179 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestRefundableRemaining(t *testing.T) {
+	m := gotropipay.Movement{Amount: 10000, Currency: "EUR"}
+	got := m.RefundableRemaining([]gotropipay.Refund{
+		{Amount: gotropipay.NewMoney(2500, "EUR"), State: gotropipay.MovementStateCompleted},
+		{Amount: gotropipay.NewMoney(1000, "EUR"), State: gotropipay.MovementStatePending},
+		{Amount: gotropipay.NewMoney(5000, "EUR"), State: gotropipay.MovementStateFailed},
+	})
+	if got != gotropipay.NewMoney(6500, "EUR") {
+		t.Errorf("remaining = %v, want 65.00 EUR", got)
+	}
+}
+
+func TestRefundMovement(t *testing.T) {
+	var keys []string
+	var bodies []map[string]interface{}
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/movements/7":
+			writeJSON(w, map[string]interface{}{"id": 7, "amount": 10000, "currency": "EUR", "state": "Completed"})
+		case "/movements/7/refunds":
+			writeJSON(w, []map[string]interface{}{{"id": 1, "amount": 4000, "currency": "EUR", "state": "completed"}})
+		case "/movements/7/refund":
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+			writeJSON(w, map[string]interface{}{"id": 2, "amount": body["amount"], "currency": "EUR", "state": "Pending"})
+		case "/movements/8":
+			writeJSON(w, map[string]interface{}{"id": 8, "amount": 500, "currency": "EUR", "state": "pending"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	ref, err := client.RefundMovement(ctx, "7", gotropipay.NewMoney(1500, ""), "damaged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.ID != "2" || ref.MovementID != "7" || ref.State != gotropipay.MovementStatePending || ref.Amount != gotropipay.NewMoney(1500, "EUR") {
+		t.Errorf("refund = %+v", ref)
+	}
+	if _, err := client.RefundMovement(ctx, "7", gotropipay.NewMoney(1500, "EUR"), "damaged"); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] == keys[1] {
+		t.Errorf("keys = %q, want two distinct refunds", keys)
+	}
+
+	// A zero amount refunds the rest
+	if _, err := client.RefundMovement(ctx, "7", gotropipay.Money{}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if bodies[2]["amount"] != float64(6000) {
+		t.Errorf("full refund amount = %v, want 6000", bodies[2]["amount"])
+	}
+
+	if _, err := client.RefundMovement(ctx, "7", gotropipay.NewMoney(6001, "EUR"), ""); !errors.Is(err, gotropipay.ErrRefundExceedsRemaining) {
+		t.Errorf("err = %v, want ErrRefundExceedsRemaining", err)
+	}
+	if _, err := client.RefundMovement(ctx, "8", gotropipay.Money{}, ""); !errors.Is(err, gotropipay.ErrMovementNotRefundable) {
+		t.Errorf("err = %v, want ErrMovementNotRefundable", err)
+	}
+}
+
+// refundServer is a movement of 100.00 EUR whose refund endpoint
+// deduplicates by Idempotency-Key and is slow the first time
+type refundServer struct {
+	mu      sync.Mutex
+	byKey   map[string]map[string]interface{}
+	refunds []map[string]interface{}
+	posts   int
+}
+
+func (s *refundServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.URL.Path {
+	case "/movements/7":
+		writeJSON(w, map[string]interface{}{"id": 7, "amount": 10000, "currency": "EUR", "state": "completed"})
+	case "/movements/7/refunds":
+		writeJSON(w, s.refunds)
+	case "/movements/7/refund":
+		s.posts++
+		key := r.Header.Get("Idempotency-Key")
+		if prev, ok := s.byKey[key]; ok {
+			writeJSON(w, prev)
+			return
+		}
+		var body struct{ Amount int64 }
+		json.NewDecoder(r.Body).Decode(&body)
+		refunded := int64(0)
+		for _, f := range s.refunds {
+			refunded += f["amount"].(int64)
+		}
+		if refunded+body.Amount > 10000 {
+			http.Error(w, `{"error":"exceeds refundable amount"}`, http.StatusUnprocessableEntity)
+			return
+		}
+		ref := map[string]interface{}{"id": len(s.refunds) + 1, "amount": body.Amount, "currency": "EUR", "state": "pending"}
+		s.byKey[key] = ref
+		s.refunds = append(s.refunds, ref)
+		if s.posts == 1 {
+			// Booked, but the client gives up before the answer arrives
+			s.mu.Unlock()
+			time.Sleep(200 * time.Millisecond)
+			s.mu.Lock()
+		}
+		writeJSON(w, ref)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestRefundRetryAfterTimeout(t *testing.T) {
+	for _, amount := range []gotropipay.Money{gotropipay.NewMoney(6000, "EUR"), {}} {
+		srv := &refundServer{byKey: map[string]map[string]interface{}{}}
+		client := newFakeClient(t, srv)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err := client.RefundMovement(ctx, "7", amount, "damaged")
+		cancel()
+		var rerr *gotropipay.RefundError
+		if !errors.As(err, &rerr) || rerr.IdempotencyKey == "" {
+			t.Fatalf("amount %v: err = %v, want *RefundError with key", amount, err)
+		}
+
+		// Retrying with the same key and amount returns the refund that went through
+		req := &gotropipay.RefundRequest{MovementID: "7", Amount: rerr.Amount, Reason: "damaged", IdempotencyKey: rerr.IdempotencyKey}
+		ref, err := client.CreateRefund(context.Background(), req)
+		if err != nil {
+			t.Fatalf("amount %v: retry: %v", amount, err)
+		}
+		if ref.ID != "1" || len(srv.refunds) != 1 {
+			t.Errorf("amount %v: refund %+v, %d refunds issued; want the original only", amount, ref, len(srv.refunds))
+		}
+		wantAmount := gotropipay.NewMoney(6000, "EUR")
+		if amount.IsZero() {
+			wantAmount = gotropipay.NewMoney(10000, "EUR")
+		}
+		if ref.Amount != wantAmount {
+			t.Errorf("amount %v: refunded %v, want %v", amount, ref.Amount, wantAmount)
+		}
+	}
+}
+
+func TestCreateRefundRecordsKeyAndAmount(t *testing.T) {
+	srv := &refundServer{byKey: map[string]map[string]interface{}{}, posts: 1} // not slow
+	client := newFakeClient(t, srv)
+
+	req := &gotropipay.RefundRequest{MovementID: "7"}
+	if _, err := client.CreateRefund(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if req.IdempotencyKey == "" || req.Amount != gotropipay.NewMoney(10000, "EUR") {
+		t.Errorf("req = %+v, want key and resolved amount recorded", req)
+	}
+	// Sending the same request again is a retry, not a second refund
+	if _, err := client.CreateRefund(context.Background(), req); err != nil || len(srv.refunds) != 1 {
+		t.Errorf("resend: err %v, %d refunds", err, len(srv.refunds))
+	}
+	if _, err := client.CreateRefund(context.Background(), &gotropipay.RefundRequest{MovementID: "7", IdempotencyKey: "k"}); err == nil {
+		t.Error("expected error for a retry without amount")
+	}
+}
@@ -11,18 +11,66 @@ import (
 
 // Request executes an HTTP request with authentication
 func (c *Client) Request(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	return c.requestWithHeaders(ctx, method, path, nil, body, result)
+}
+
+// requestWithHeaders is Request with extra request headers
+func (c *Client) requestWithHeaders(ctx context.Context, method, path string, header http.Header, body interface{}, result interface{}) error {
+	resp, err := c.send(ctx, method, path, header, body)
+	if err != nil {
+		return err
+	}
+
+	// Handle specific error codes or generic 4xx/5xx
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("API error: %s (status: %d) - %s", resp.URL, resp.StatusCode, string(resp.Body))
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(resp.Body, result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// rawResponse is a fully read HTTP response
+type rawResponse struct {
+	URL        string
+	StatusCode int
+	Body       []byte
+}
+
+// send performs an authenticated request and reads the whole body,
+// leaving status code handling to the caller. Secrets of a sensitiveBody are
+// wiped once the request reaches the transport, and redacted from error bodies.
+func (c *Client) send(ctx context.Context, method, path string, header http.Header, body interface{}) (*rawResponse, error) {
+	var secrets []SensitiveString
+	if sb, ok := body.(sensitiveBody); ok {
+		secrets = sb.secrets()
+		for _, s := range secrets {
+			if s.IsZero() {
+				return nil, ErrSensitiveValueMissing
+			}
+		}
+		body = sb.wireBody()
+	}
+
 	// Get Token
 	token, err := c.auth.GetToken()
 	if err != nil {
-		return fmt.Errorf("failed to get token: %w", err)
+		return nil, fmt.Errorf("failed to get token: %w", err)
 	}
 
 	var reqBody io.Reader
 	if body != nil {
 		jsonBytes, err := json.Marshal(body)
 		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
+		// The encoded body may hold revealed secrets
+		defer clear(jsonBytes)
 		reqBody = bytes.NewBuffer(jsonBytes)
 	}
 
@@ -33,31 +81,31 @@ func (c *Client) Request(ctx context.Context, method, path string, body interfac
 
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	for k, v := range header {
+		req.Header[k] = v
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	resp, err := c.httpClient.Do(req)
+	// Once handed to the transport the secrets are spent, whatever the outcome
+	defer zeroSecrets(secrets)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	// Handle specific error codes or generic 4xx/5xx
-	if resp.StatusCode >= 400 {
-		// Try to parse error body if any
-		errResp := new(bytes.Buffer)
-		_, _ = io.Copy(errResp, resp.Body)
-		return fmt.Errorf("API error: %s (status: %d) - %s", req.URL.String(), resp.StatusCode, errResp.String())
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
-
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
+	if resp.StatusCode >= 400 {
+		// Error bodies may echo the request
+		data = redactSecrets(data, secrets)
 	}
 
-	return nil
+	return &rawResponse{URL: req.URL.String(), StatusCode: resp.StatusCode, Body: data}, nil
 }
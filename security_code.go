@@ -0,0 +1,195 @@
+package gotropipay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tropipay/gotropipay/totp"
+)
+
+// ErrSecurityCodeRequired is returned when an operation needs a security code,
+// none was passed and the client has no SecurityCodeProvider
+var ErrSecurityCodeRequired = errors.New("security code is required")
+
+// SecurityCodeRequest describes the operation asking for step-up authentication
+type SecurityCodeRequest struct {
+	Operation string // e.g. "createPayout", "deleteDepositAccount"
+	Resource  string // ID or reference of the object concerned
+}
+
+// SecurityCodeProvider supplies security codes for sensitive operations. The
+// client calls it whenever such an operation is invoked without a code.
+type SecurityCodeProvider interface {
+	SecurityCode(ctx context.Context, req SecurityCodeRequest) (string, error)
+}
+
+// SecurityCodeFunc adapts a function to SecurityCodeProvider
+type SecurityCodeFunc func(ctx context.Context, req SecurityCodeRequest) (string, error)
+
+// SecurityCode calls f
+func (f SecurityCodeFunc) SecurityCode(ctx context.Context, req SecurityCodeRequest) (string, error) {
+	return f(ctx, req)
+}
+
+// TOTPProvider generates codes from a stored authenticator secret, for
+// accounts with TOTP two-factor authentication enabled
+type TOTPProvider struct {
+	Secret  string // Base32, as returned by Get2FASecret
+	Options totp.Options
+}
+
+// SecurityCode returns the current TOTP code
+func (p TOTPProvider) SecurityCode(ctx context.Context, req SecurityCodeRequest) (string, error) {
+	return p.Options.Generate(p.Secret, time.Now())
+}
+
+// TerminalPrompt asks a human for each code. If Delivery is set, a code is
+// sent by SMS or email first. Prompts are serialised, so one prompt can be
+// shared by concurrent operations. In is read by a single background
+// goroutine for the life of the prompt, so a cancelled prompt leaves no
+// reader behind. Lines typed before a prompt is shown, such as a late answer
+// to a cancelled one, are discarded.
+type TerminalPrompt struct {
+	Delivery *SendSecurityCodeRequest
+	In       io.Reader // Defaults to os.Stdin; set before the first prompt
+	Out      io.Writer // Defaults to os.Stderr
+
+	client *Client
+	mu     sync.Mutex // Serialises prompts
+	start  sync.Once
+	shown  atomic.Uint64 // Number of the prompt currently shown
+
+	queueMu sync.Mutex
+	queue   []promptLine
+	ready   chan struct{} // Signalled when a line is queued
+}
+
+// promptLine is a line read from In, tagged with the prompt shown when it
+// was read
+type promptLine struct {
+	text   string
+	err    error
+	prompt uint64
+}
+
+// NewTerminalPrompt returns a prompt that requests delivery of each code
+// through c. Pass a nil delivery for codes from an authenticator app.
+func (c *Client) NewTerminalPrompt(delivery *SendSecurityCodeRequest) *TerminalPrompt {
+	return &TerminalPrompt{Delivery: delivery, client: c}
+}
+
+// SecurityCode sends a code if configured and reads it from the terminal
+func (p *TerminalPrompt) SecurityCode(ctx context.Context, req SecurityCodeRequest) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := p.Out
+	if out == nil {
+		out = os.Stderr
+	}
+
+	if p.Delivery != nil && p.client != nil {
+		if err := p.client.SendSecurityCode(ctx, *p.Delivery); err != nil {
+			return "", fmt.Errorf("failed to send security code: %w", err)
+		}
+		fmt.Fprintf(out, "A security code was sent by %s.\n", p.Delivery.Type)
+	}
+	prompt := p.shown.Add(1)
+	fmt.Fprintf(out, "Security code for %s %s: ", req.Operation, req.Resource)
+	p.start.Do(func() {
+		in := p.In
+		if in == nil {
+			in = os.Stdin
+		}
+		p.ready = make(chan struct{}, 1)
+		go p.readLines(in)
+	})
+
+	l, err := p.next(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	code := strings.TrimSpace(l.text)
+	if code == "" {
+		if l.err != nil {
+			return "", fmt.Errorf("failed to read security code: %w", l.err)
+		}
+		return "", ErrSecurityCodeRequired
+	}
+	return code, nil
+}
+
+// next waits for the first line read while prompt was shown. Older lines are
+// dropped. A read error stays queued so later prompts fail too.
+func (p *TerminalPrompt) next(ctx context.Context, prompt uint64) (promptLine, error) {
+	for {
+		p.queueMu.Lock()
+		for len(p.queue) > 0 {
+			l := p.queue[0]
+			if l.err != nil {
+				if l.prompt < prompt {
+					l.text = ""
+				}
+				p.queue[0].text = ""
+				p.queueMu.Unlock()
+				return l, nil
+			}
+			p.queue = p.queue[1:]
+			if l.prompt == prompt {
+				p.queueMu.Unlock()
+				return l, nil
+			}
+		}
+		p.queueMu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return promptLine{}, ctx.Err()
+		case <-p.ready:
+		}
+	}
+}
+
+// readLines queues every line of in until the first read error
+func (p *TerminalPrompt) readLines(in io.Reader) {
+	r := bufio.NewReader(in)
+	for {
+		text, err := r.ReadString('\n')
+		p.queueMu.Lock()
+		p.queue = append(p.queue, promptLine{text, err, p.shown.Load()})
+		p.queueMu.Unlock()
+		select {
+		case p.ready <- struct{}{}:
+		default:
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// securityCode returns given if set, otherwise asks the configured provider
+func (c *Client) securityCode(ctx context.Context, given string, req SecurityCodeRequest) (string, error) {
+	if given != "" {
+		return given, nil
+	}
+	if c.securityCodes == nil {
+		return "", fmt.Errorf("%s: %w", req.Operation, ErrSecurityCodeRequired)
+	}
+	code, err := c.securityCodes.SecurityCode(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to obtain security code: %w", req.Operation, err)
+	}
+	if code == "" {
+		return "", fmt.Errorf("%s: %w", req.Operation, ErrSecurityCodeRequired)
+	}
+	return code, nil
+}
@@ -0,0 +1,115 @@
+package gotropipay_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestSecurityCodeProviderSuppliesMissingCodes(t *testing.T) {
+	var sentCode string
+	var delivered int
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/users/sendSecurityCode":
+			delivered++
+		case r.Method == "DELETE":
+			var body gotropipay.DeleteDepositAccountRequest
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			sentCode = body.SecurityCode
+		}
+		writeJSON(w, map[string]interface{}{})
+	})
+	ctx := context.Background()
+
+	// No provider: the call fails before reaching the API
+	plain := newFakeClient(t, api)
+	if err := plain.DeleteDepositAccount(ctx, 5, ""); !errors.Is(err, gotropipay.ErrSecurityCodeRequired) {
+		t.Fatalf("err = %v, want ErrSecurityCodeRequired", err)
+	}
+
+	var asked gotropipay.SecurityCodeRequest
+	client := newFakeClient(t, api, gotropipay.WithSecurityCodeProvider(gotropipay.SecurityCodeFunc(
+		func(_ context.Context, req gotropipay.SecurityCodeRequest) (string, error) {
+			asked = req
+			return "424242", nil
+		})))
+
+	if err := client.DeleteDepositAccount(ctx, 5, ""); err != nil {
+		t.Fatal(err)
+	}
+	if sentCode != "424242" || asked.Operation != "deleteDepositAccount" || asked.Resource != "5" {
+		t.Errorf("sent %q for %+v", sentCode, asked)
+	}
+
+	// Terminal prompt requests delivery, then reads the code
+	var out bytes.Buffer
+	prompt := plain.NewTerminalPrompt(&gotropipay.SendSecurityCodeRequest{Type: "email"})
+	prompt.In, prompt.Out = strings.NewReader(" 777000 \n"), &out
+	prompted := newFakeClient(t, api, gotropipay.WithSecurityCodeProvider(prompt))
+
+	if err := prompted.DeleteDepositAccount(ctx, 6, ""); err != nil {
+		t.Fatal(err)
+	}
+	if sentCode != "777000" || delivered != 1 || !strings.Contains(out.String(), "deleteDepositAccount 6") {
+		t.Errorf("sent %q, delivered %d, prompt %q", sentCode, delivered, out.String())
+	}
+}
+
+func TestTerminalPromptSharesOneReader(t *testing.T) {
+	client := newFakeClient(t, http.NotFoundHandler())
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	prompt := client.NewTerminalPrompt(nil)
+	prompt.In, prompt.Out = pr, io.Discard
+	req := gotropipay.SecurityCodeRequest{Operation: "createPayout"}
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := prompt.SecurityCode(ctx, req); !errors.Is(err, context.Canceled) {
+			t.Fatalf("err = %v, want context.Canceled", err)
+		}
+	}
+	if n := runtime.NumGoroutine(); n > before+1 {
+		t.Errorf("%d goroutines after cancelled prompts, started with %d", n, before)
+	}
+
+	// Answers to a cancelled prompt are not taken by the next one
+	io.WriteString(pw, "111111\n")
+	io.WriteString(pw, "333333\n")
+	time.Sleep(20 * time.Millisecond)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(pw, "222222\n")
+	}()
+	code, err := prompt.SecurityCode(context.Background(), req)
+	if err != nil || code != "222222" {
+		t.Errorf("code = %q, %v; want 222222", code, err)
+	}
+}
+
+func TestCreatePayoutValidatesBeforePrompting(t *testing.T) {
+	var asked int
+	client := newFakeClient(t, http.NotFoundHandler(), gotropipay.WithSecurityCodeProvider(
+		gotropipay.SecurityCodeFunc(func(context.Context, gotropipay.SecurityCodeRequest) (string, error) {
+			asked++
+			return "123456", nil
+		})))
+	if _, err := client.CreatePayout(context.Background(), gotropipay.PayoutRequest{Amount: gotropipay.NewMoney(100, "EUR")}); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if asked != 0 {
+		t.Errorf("provider asked %d times for an invalid payout", asked)
+	}
+}
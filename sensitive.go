@@ -0,0 +1,135 @@
+package gotropipay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// redacted replaces sensitive values wherever they would be printed
+const redacted = "[REDACTED]"
+
+// ErrSensitiveValueMissing is returned when a request carries an empty or
+// already wiped SensitiveString. Build a new request to retry.
+var ErrSensitiveValueMissing = errors.New("sensitive field is empty or was already sent")
+
+// SensitiveString holds a card number, CVC or PIN. It prints, logs and
+// marshals as [REDACTED]; only the transport sees the real value, and the
+// SDK wipes it once the request that carried it has been sent, whether or
+// not it succeeded. Copies share the same storage, so wiping one wipes them
+// all: a request value cannot be reused, even to retry the same call.
+type SensitiveString struct {
+	v *sensitiveValue
+}
+
+type sensitiveValue struct {
+	b []byte
+}
+
+// NewSensitiveString copies s into a wipeable buffer. The original string
+// cannot be wiped; prefer SensitiveBytes when reading secrets from input.
+func NewSensitiveString(s string) SensitiveString {
+	return SensitiveString{v: &sensitiveValue{b: []byte(s)}}
+}
+
+// SensitiveBytes takes ownership of b, which is zeroed after send
+func SensitiveBytes(b []byte) SensitiveString {
+	return SensitiveString{v: &sensitiveValue{b: b}}
+}
+
+// Reveal returns the plain value. The returned string is a copy that cannot be wiped.
+func (s SensitiveString) Reveal() string {
+	if s.v == nil {
+		return ""
+	}
+	return string(s.v.b)
+}
+
+// IsZero reports whether the value is empty or has been wiped
+func (s SensitiveString) IsZero() bool {
+	return s.v == nil || len(s.v.b) == 0
+}
+
+// Zero overwrites the value in memory and leaves it empty
+func (s SensitiveString) Zero() {
+	if s.v == nil {
+		return
+	}
+	clear(s.v.b)
+	s.v.b = nil
+}
+
+// String implements fmt.Stringer
+func (s SensitiveString) String() string { return redacted }
+
+// GoString implements fmt.GoStringer
+func (s SensitiveString) GoString() string { return `gotropipay.SensitiveString("` + redacted + `")` }
+
+// Format implements fmt.Formatter so every verb, including %x and %d, is redacted
+func (s SensitiveString) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprint(f, s.GoString())
+	case verb == 'q':
+		fmt.Fprintf(f, "%q", redacted)
+	default:
+		fmt.Fprint(f, redacted)
+	}
+}
+
+// LogValue implements slog.LogValuer
+func (s SensitiveString) LogValue() slog.Value { return slog.StringValue(redacted) }
+
+// MarshalJSON always redacts; request bodies are encoded through wireBody instead
+func (s SensitiveString) MarshalJSON() ([]byte, error) { return []byte(`"` + redacted + `"`), nil }
+
+// wireSecret is a SensitiveString as the transport encodes it
+type wireSecret SensitiveString
+
+// MarshalJSON writes the real value as a JSON string
+func (w wireSecret) MarshalJSON() ([]byte, error) {
+	var b []byte
+	if w.v != nil {
+		b = w.v.b
+	}
+	out := make([]byte, 0, len(b)+2)
+	out = append(out, '"')
+	for _, c := range b {
+		switch {
+		case c == '"' || c == '\\':
+			out = append(out, '\\', c)
+		case c < 0x20:
+			out = fmt.Appendf(out, `\u%04x`, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return append(out, '"'), nil
+}
+
+// sensitiveBody is implemented by request payloads carrying SensitiveString fields
+type sensitiveBody interface {
+	// wireBody returns the payload with its secrets revealed for encoding
+	wireBody() interface{}
+	// secrets lists the values to redact from errors and wipe after send
+	secrets() []SensitiveString
+}
+
+// redactSecrets replaces every occurrence of the given secrets in data
+func redactSecrets(data []byte, secrets []SensitiveString) []byte {
+	for _, s := range secrets {
+		if s.v == nil || len(s.v.b) < 3 { // too short to redact without mangling unrelated text
+			continue
+		}
+		data = bytes.ReplaceAll(data, s.v.b, []byte(redacted))
+	}
+	return data
+}
+
+// zeroSecrets wipes every secret of a request body
+func zeroSecrets(secrets []SensitiveString) {
+	for _, s := range secrets {
+		s.Zero()
+	}
+}
@@ -0,0 +1,149 @@
+package gotropipay_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+)
+
+const (
+	testPAN = "4111111111111111"
+	testCVC = "737"
+	testPIN = "9402"
+)
+
+func TestSensitiveStringNeverPrinted(t *testing.T) {
+	req := gotropipay.CreatePaymentCardRequest{
+		Number:     gotropipay.NewSensitiveString(testPAN),
+		CVC:        gotropipay.NewSensitiveString(testCVC),
+		HolderName: "Jane Doe",
+	}
+	tropicard := gotropipay.AddTropicardAccountRequest{TropicardNumber: "1234", Pin: gotropipay.NewSensitiveString(testPIN)}
+
+	var out bytes.Buffer
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d"} {
+		fmt.Fprintf(&out, verb+"\n", req)
+		fmt.Fprintf(&out, verb+"\n", &tropicard)
+		fmt.Fprintf(&out, verb+"\n", req.Number)
+	}
+	fmt.Fprintln(&out, req.Number.String(), req.CVC.GoString())
+	js, _ := json.Marshal(req)
+	out.Write(js)
+	js, _ = json.Marshal(tropicard)
+	out.Write(js)
+	logger := slog.New(slog.NewJSONHandler(&out, nil))
+	logger.Info("card", "req", req, "number", req.Number, "pin", tropicard.Pin)
+	slog.New(slog.NewTextHandler(&out, nil)).Info("card", "number", req.Number)
+
+	assertNoSecrets(t, out.String())
+	if req.Number.Reveal() != testPAN {
+		t.Errorf("Reveal = %q", req.Number.Reveal())
+	}
+}
+
+func TestSensitiveStringTransport(t *testing.T) {
+	var got map[string]interface{}
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		// Echo the request back, as some error responses do
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(body)
+	}))
+	ctx := context.Background()
+
+	req := gotropipay.CreatePaymentCardRequest{
+		Number: gotropipay.NewSensitiveString(testPAN),
+		CVC:    gotropipay.NewSensitiveString(testCVC),
+	}
+	_, err := client.CreatePaymentCard(ctx, req)
+	if err == nil {
+		t.Fatal("expected API error")
+	}
+	if got["number"] != testPAN || got["cvc"] != testCVC {
+		t.Errorf("server received %v", got)
+	}
+	assertNoSecrets(t, err.Error())
+	if !req.Number.IsZero() || !req.CVC.IsZero() {
+		t.Error("secrets not wiped after send")
+	}
+
+	pin := gotropipay.SensitiveBytes([]byte(testPIN))
+	_, err = client.AddTropicardAccount(ctx, gotropipay.AddTropicardAccountRequest{TropicardNumber: "1234", Pin: pin})
+	if err == nil || got["pin"] != testPIN {
+		t.Fatalf("err = %v, server received %v", err, got)
+	}
+	assertNoSecrets(t, err.Error())
+	if !pin.IsZero() {
+		t.Error("pin not wiped after send")
+	}
+}
+
+func assertNoSecrets(t *testing.T, s string) {
+	t.Helper()
+	for _, secret := range []string{testPAN, testCVC, testPIN, fmt.Sprintf("%x", testPAN)} {
+		if strings.Contains(s, secret) {
+			t.Errorf("output leaks %q:\n%s", secret, s)
+		}
+	}
+	if !strings.Contains(s, "[REDACTED]") {
+		t.Errorf("output has no redaction marker:\n%s", s)
+	}
+}
+
+func TestSensitiveRequestCannotBeReused(t *testing.T) {
+	var calls int
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	ctx := context.Background()
+
+	req := gotropipay.CreatePaymentCardRequest{
+		Number: gotropipay.NewSensitiveString(testPAN),
+		CVC:    gotropipay.NewSensitiveString(testCVC),
+	}
+	if _, err := client.CreatePaymentCard(ctx, req); err == nil {
+		t.Fatal("expected API error")
+	}
+	// The secrets were sent and wiped, so a blind retry must not send empty values
+	if _, err := client.CreatePaymentCard(ctx, req); !errors.Is(err, gotropipay.ErrSensitiveValueMissing) {
+		t.Errorf("retry err = %v, want ErrSensitiveValueMissing", err)
+	}
+	if _, err := client.CreatePaymentCard(ctx, gotropipay.CreatePaymentCardRequest{Number: gotropipay.NewSensitiveString(testPAN)}); !errors.Is(err, gotropipay.ErrSensitiveValueMissing) {
+		t.Errorf("missing CVC err = %v, want ErrSensitiveValueMissing", err)
+	}
+	if calls != 1 {
+		t.Errorf("%d requests reached the server, want 1", calls)
+	}
+}
+
+func TestSensitiveValuesKeptWhenNothingWasSent(t *testing.T) {
+	// Token retrieval fails, so the card request never reaches the transport
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	client := gotropipay.NewClient("id", "secret", gotropipay.WithBaseURL(srv.URL))
+
+	req := gotropipay.CreatePaymentCardRequest{
+		Number: gotropipay.NewSensitiveString(testPAN),
+		CVC:    gotropipay.NewSensitiveString(testCVC),
+	}
+	if _, err := client.CreatePaymentCard(context.Background(), req); err == nil {
+		t.Fatal("expected token error")
+	}
+	if req.Number.Reveal() != testPAN || req.CVC.Reveal() != testCVC {
+		t.Error("secrets wiped although nothing was sent")
+	}
+}
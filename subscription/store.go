@@ -0,0 +1,73 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrConflict is returned by Store.Save when the subscription changed since it was read
+var ErrConflict = errors.New("subscription was modified concurrently")
+
+// Store persists subscriptions. MemoryStore is provided for tests; production
+// code typically backs this with its own database.
+type Store interface {
+	// Get returns nil, nil when the subscription is unknown
+	Get(ctx context.Context, id string) (*Subscription, error)
+	// Save writes sub only if the stored copy's UpdatedAt equals
+	// prevUpdatedAt (zero: only if none is stored) and returns ErrConflict
+	// otherwise, e.g. UPDATE ... WHERE id = ? AND updated_at = ?
+	Save(ctx context.Context, sub *Subscription, prevUpdatedAt time.Time) error
+	// Due returns non-cancelled subscriptions with NextAttempt at or before now
+	Due(ctx context.Context, now time.Time) ([]Subscription, error)
+}
+
+// MemoryStore is an in-memory Store
+type MemoryStore struct {
+	mu   sync.Mutex
+	subs map[string]Subscription
+}
+
+// NewMemoryStore returns an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{subs: make(map[string]Subscription)}
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &sub, nil
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(ctx context.Context, sub *Subscription, prevUpdatedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur, ok := s.subs[sub.ID]
+	if ok != !prevUpdatedAt.IsZero() || (ok && !cur.UpdatedAt.Equal(prevUpdatedAt)) {
+		return ErrConflict
+	}
+	s.subs[sub.ID] = *sub
+	return nil
+}
+
+// Due implements Store, oldest attempt first
+func (s *MemoryStore) Due(ctx context.Context, now time.Time) ([]Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []Subscription
+	for _, sub := range s.subs {
+		if sub.Status != Cancelled && !sub.NextAttempt.After(now) {
+			due = append(due, sub)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttempt.Before(due[j].NextAttempt) })
+	return due, nil
+}
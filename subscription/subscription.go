@@ -0,0 +1,366 @@
+// Package subscription bills saved cards on a schedule, with dunning retries
+// for declined charges. State lives in a Store, so the scheduler can run from
+// a cron job or a long-lived process and survive restarts.
+package subscription
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// Charger is the part of the client used for billing. *gotropipay.Client implements it.
+type Charger interface {
+	ChargeSavedCard(ctx context.Context, req gotropipay.ChargeRequest) (*gotropipay.Charge, error)
+}
+
+// Interval is the unit of a billing period
+type Interval string
+
+const (
+	Daily   Interval = "day"
+	Weekly  Interval = "week"
+	Monthly Interval = "month"
+	Yearly  Interval = "year"
+)
+
+// Plan is what a subscription charges and how often
+type Plan struct {
+	ID            string
+	Amount        gotropipay.Money
+	Interval      Interval
+	IntervalCount int // Periods per charge (default 1)
+	Concept       string
+}
+
+// Status is the lifecycle state of a subscription
+type Status string
+
+const (
+	Active    Status = "active"    // Paid up
+	PastDue   Status = "past_due"  // Last charge declined, retries scheduled
+	Cancelled Status = "cancelled" // Cancelled by the merchant or after dunning ran out
+)
+
+// Subscription is the persisted state of one customer's billing
+type Subscription struct {
+	ID       string               `json:"id"`
+	Customer string               `json:"customer,omitempty"`
+	Plan     Plan                 `json:"plan"`
+	Card     gotropipay.CardToken `json:"card"`
+	Status   Status               `json:"status"`
+
+	// Anchor is the start of the first billed period; period n starts at
+	// Anchor plus n intervals, clamped to month ends
+	Anchor      time.Time `json:"anchor"`
+	PeriodsPaid int       `json:"periodsPaid"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	Attempts    int       `json:"attempts"` // Failed attempts for the current period
+
+	LastCharge *ChargeRecord `json:"lastCharge,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+}
+
+// ChargeRecord is the outcome of one billing attempt
+type ChargeRecord struct {
+	Period    int                      `json:"period"`
+	Attempt   int                      `json:"attempt"`
+	Reference string                   `json:"reference"`
+	ChargeID  string                   `json:"chargeId,omitempty"`
+	State     gotropipay.MovementState `json:"state,omitempty"`
+	Error     string                   `json:"error,omitempty"`
+	At        time.Time                `json:"at"`
+}
+
+// PaidThrough is the end of the last paid period
+func (s *Subscription) PaidThrough() time.Time {
+	return s.periodStart(s.PeriodsPaid)
+}
+
+func (s *Subscription) periodStart(n int) time.Time {
+	count := s.Plan.IntervalCount
+	if count <= 0 {
+		count = 1
+	}
+	return advance(s.Anchor, s.Plan.Interval, n*count)
+}
+
+// advance adds n intervals to t, clamping the day for months that are too short
+func advance(t time.Time, interval Interval, n int) time.Time {
+	switch interval {
+	case Daily:
+		return t.AddDate(0, 0, n)
+	case Weekly:
+		return t.AddDate(0, 0, 7*n)
+	case Yearly:
+		n *= 12
+	}
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(d, last)-1)
+}
+
+// Dunning controls retries of declined charges
+type Dunning struct {
+	// Retries are the delays after each declined attempt (default 1, 3 and 7
+	// days). When they run out the subscription is cancelled.
+	Retries []time.Duration
+	// ErrorRetry is the delay after a transport error or pending charge (default 1h)
+	ErrorRetry time.Duration
+}
+
+// EventType classifies scheduler events
+type EventType string
+
+const (
+	EventCharged   EventType = "charged"
+	EventDeclined  EventType = "declined"
+	EventError     EventType = "error"
+	EventCancelled EventType = "cancelled"
+)
+
+// Event reports what happened to a subscription during a run
+type Event struct {
+	Type         EventType
+	Subscription Subscription
+	Charge       *ChargeRecord
+}
+
+// Options configures a Scheduler
+type Options struct {
+	Dunning Dunning
+	Now     func() time.Time // Defaults to time.Now
+	OnEvent func(Event)
+}
+
+// Scheduler charges due subscriptions
+type Scheduler struct {
+	charger Charger
+	store   Store
+	opts    Options
+}
+
+// New creates a scheduler
+func New(charger Charger, store Store, opts Options) *Scheduler {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.Dunning.Retries == nil {
+		opts.Dunning.Retries = []time.Duration{24 * time.Hour, 72 * time.Hour, 7 * 24 * time.Hour}
+	}
+	if opts.Dunning.ErrorRetry <= 0 {
+		opts.Dunning.ErrorRetry = time.Hour
+	}
+	return &Scheduler{charger: charger, store: store, opts: opts}
+}
+
+// Subscribe starts billing card for plan. The first charge happens at
+// firstBilling; pass the end of the period already paid through the paylink.
+func (s *Scheduler) Subscribe(ctx context.Context, id, customer string, plan Plan, card gotropipay.CardToken, firstBilling time.Time) (*Subscription, error) {
+	if card.Token == "" {
+		return nil, errors.New("subscription requires a card token")
+	}
+	if plan.Amount.Amount <= 0 {
+		return nil, errors.New("subscription plan requires a positive amount")
+	}
+	if existing, err := s.store.Get(ctx, id); err != nil {
+		return nil, err
+	} else if existing != nil {
+		return nil, fmt.Errorf("subscription %q already exists", id)
+	}
+
+	now := s.opts.Now()
+	sub := &Subscription{
+		ID:          id,
+		Customer:    customer,
+		Plan:        plan,
+		Card:        card,
+		Status:      Active,
+		Anchor:      firstBilling,
+		NextAttempt: firstBilling,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.store.Save(ctx, sub, time.Time{}); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Cancel stops future charges. A charge already in flight completes and is
+// recorded, but the subscription stays cancelled.
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	for attempt := 0; ; attempt++ {
+		sub, err := s.store.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if sub == nil {
+			return fmt.Errorf("subscription %q not found", id)
+		}
+		prev := sub.UpdatedAt
+		sub.Status = Cancelled
+		s.touch(sub)
+		err = s.store.Save(ctx, sub, prev)
+		if !errors.Is(err, ErrConflict) || attempt == maxSaveAttempts-1 {
+			return err
+		}
+	}
+}
+
+// maxSaveAttempts bounds re-reads after ErrConflict
+const maxSaveAttempts = 3
+
+// touch stamps sub as modified now. UpdatedAt always moves forward so
+// conditional saves notice every write, even with a coarse clock.
+func (s *Scheduler) touch(sub *Subscription) {
+	now := s.opts.Now()
+	if !now.After(sub.UpdatedAt) {
+		now = sub.UpdatedAt.Add(time.Nanosecond)
+	}
+	sub.UpdatedAt = now
+}
+
+// RunOnce charges every subscription whose next attempt is due and returns
+// the number charged
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	due, err := s.store.Due(ctx, s.opts.Now())
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := range due {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		charged, err := s.bill(ctx, due[i].ID)
+		if err != nil {
+			return n, err
+		}
+		if charged {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Run calls RunOnce every interval until ctx is done
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// bill makes one attempt for the current period and reports whether a charge
+// was attempted. Only store errors are returned; charge outcomes are recorded
+// on the subscription.
+func (s *Scheduler) bill(ctx context.Context, id string) (bool, error) {
+	// Re-read right before charging: the Due snapshot may predate a Cancel
+	sub, err := s.store.Get(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if sub == nil || sub.Status == Cancelled || sub.NextAttempt.After(s.opts.Now()) {
+		return false, nil
+	}
+	prev := sub.UpdatedAt
+
+	period := sub.PeriodsPaid
+	rec := &ChargeRecord{
+		Period:    period,
+		Attempt:   sub.Attempts + 1,
+		Reference: fmt.Sprintf("%s-%s", sub.ID, sub.periodStart(period).Format("20060102")),
+	}
+
+	// The key is stable per attempt, so a crash after charging but before
+	// saving replays the same charge instead of billing twice
+	key := sha256.Sum256([]byte(fmt.Sprintf("%s#%d", rec.Reference, rec.Attempt)))
+	charge, err := s.charger.ChargeSavedCard(ctx, gotropipay.ChargeRequest{
+		Token:          sub.Card.Token,
+		Amount:         sub.Plan.Amount,
+		Reference:      rec.Reference,
+		Concept:        sub.Plan.Concept,
+		IdempotencyKey: hex.EncodeToString(key[:16]),
+	})
+	now := s.opts.Now()
+	s.touch(sub)
+	rec.At = now
+	sub.LastCharge = rec
+
+	var event EventType
+	switch {
+	case err != nil:
+		rec.Error = err.Error()
+		sub.NextAttempt = now.Add(s.opts.Dunning.ErrorRetry)
+		event = EventError
+	case charge.Succeeded():
+		rec.ChargeID, rec.State = charge.ID, charge.State
+		sub.PeriodsPaid++
+		sub.Attempts = 0
+		sub.Status = Active
+		sub.NextAttempt = sub.periodStart(sub.PeriodsPaid)
+		event = EventCharged
+	case !charge.State.IsTerminal():
+		// Pending: ask again later with the same key to learn the outcome
+		rec.ChargeID, rec.State = charge.ID, charge.State
+		sub.NextAttempt = now.Add(s.opts.Dunning.ErrorRetry)
+		event = EventError
+	default:
+		rec.ChargeID, rec.State, rec.Error = charge.ID, charge.State, charge.DeclineReason
+		sub.Attempts++
+		if sub.Attempts > len(s.opts.Dunning.Retries) {
+			sub.Status = Cancelled
+			event = EventCancelled
+		} else {
+			sub.Status = PastDue
+			sub.NextAttempt = now.Add(s.opts.Dunning.Retries[sub.Attempts-1])
+			event = EventDeclined
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := s.store.Save(ctx, sub, prev)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrConflict) || attempt == maxSaveAttempts-1 {
+			return true, fmt.Errorf("failed to save subscription %s: %w", sub.ID, err)
+		}
+		// Changed while charging, typically cancelled. The charge happened
+		// either way, so record it on top of the other change.
+		fresh, err := s.store.Get(ctx, sub.ID)
+		if err != nil {
+			return true, fmt.Errorf("failed to save subscription %s: %w", sub.ID, err)
+		}
+		if fresh == nil {
+			return true, fmt.Errorf("subscription %s was deleted while charging", sub.ID)
+		}
+		prev = fresh.UpdatedAt
+		fresh.PeriodsPaid, fresh.Attempts, fresh.NextAttempt, fresh.LastCharge = sub.PeriodsPaid, sub.Attempts, sub.NextAttempt, sub.LastCharge
+		if fresh.Status != Cancelled {
+			fresh.Status = sub.Status
+		}
+		s.touch(fresh)
+		sub = fresh
+	}
+	if s.opts.OnEvent != nil {
+		s.opts.OnEvent(Event{Type: event, Subscription: *sub, Charge: rec})
+	}
+	return true, nil
+}
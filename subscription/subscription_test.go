@@ -0,0 +1,221 @@
+package subscription_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+	"github.com/tropipay/gotropipay/subscription"
+)
+
+type fakeCharger struct {
+	declines int
+	pending  int
+	errs     int
+	onCharge func() // Runs while the charge is in flight
+	keys     []string
+}
+
+func (f *fakeCharger) ChargeSavedCard(ctx context.Context, req gotropipay.ChargeRequest) (*gotropipay.Charge, error) {
+	f.keys = append(f.keys, req.IdempotencyKey)
+	if f.onCharge != nil {
+		f.onCharge()
+	}
+	if f.errs > 0 {
+		f.errs--
+		return nil, errors.New("connection reset")
+	}
+	state := gotropipay.MovementStateCompleted
+	switch {
+	case f.pending > 0:
+		f.pending--
+		state = gotropipay.MovementStatePending
+	case f.declines > 0:
+		f.declines--
+		state = gotropipay.MovementStateFailed
+	}
+	return &gotropipay.Charge{ID: req.Reference, Reference: req.Reference, State: state, Amount: req.Amount}, nil
+}
+
+// cancelAfterDue cancels a subscription between Due and billing
+type cancelAfterDue struct {
+	*subscription.MemoryStore
+	cancel func()
+}
+
+func (s cancelAfterDue) Due(ctx context.Context, now time.Time) ([]subscription.Subscription, error) {
+	due, err := s.MemoryStore.Due(ctx, now)
+	s.cancel()
+	return due, err
+}
+
+func TestSchedulerDunningAndPeriods(t *testing.T) {
+	now := time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)
+	charger := &fakeCharger{declines: 2}
+	store := subscription.NewMemoryStore()
+	var events []subscription.EventType
+	s := subscription.New(charger, store, subscription.Options{
+		Now:     func() time.Time { return now },
+		OnEvent: func(e subscription.Event) { events = append(events, e.Type) },
+	})
+	ctx := context.Background()
+
+	plan := subscription.Plan{ID: "pro", Amount: gotropipay.NewMoney(999, "EUR"), Interval: subscription.Monthly}
+	if _, err := s.Subscribe(ctx, "sub-1", "cust-1", plan, gotropipay.CardToken{Token: "tok"}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Declined, retried after 1 day, declined, retried after 3 days, paid
+	for _, step := range []time.Duration{0, 24 * time.Hour, 72 * time.Hour} {
+		now = now.Add(step)
+		if n, err := s.RunOnce(ctx); err != nil || n != 1 {
+			t.Fatalf("RunOnce = %d, %v", n, err)
+		}
+	}
+	sub, _ := store.Get(ctx, "sub-1")
+	if sub.Status != subscription.Active || sub.PeriodsPaid != 1 {
+		t.Fatalf("after dunning: %+v", sub)
+	}
+	// Jan 31 anchor: next period starts on the last day of February
+	if want := time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC); !sub.NextAttempt.Equal(want) {
+		t.Errorf("next attempt %v, want %v", sub.NextAttempt, want)
+	}
+	if n, _ := s.RunOnce(ctx); n != 0 {
+		t.Error("nothing should be due before the next period")
+	}
+	if charger.keys[0] == charger.keys[1] {
+		t.Error("retries must use a new idempotency key")
+	}
+
+	want := []subscription.EventType{subscription.EventDeclined, subscription.EventDeclined, subscription.EventCharged}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("events = %v, want %v", events, want)
+			break
+		}
+	}
+
+	// Running out of retries cancels
+	charger.declines = 10
+	now = sub.NextAttempt
+	for i := 0; i < 4; i++ {
+		if _, err := s.RunOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(8 * 24 * time.Hour)
+	}
+	sub, _ = store.Get(ctx, "sub-1")
+	if sub.Status != subscription.Cancelled || sub.PeriodsPaid != 1 {
+		t.Errorf("after exhausted dunning: %+v", sub)
+	}
+}
+
+func newTestScheduler(t *testing.T, charger *fakeCharger, store subscription.Store, now *time.Time) *subscription.Scheduler {
+	t.Helper()
+	s := subscription.New(charger, store, subscription.Options{Now: func() time.Time { return *now }})
+	plan := subscription.Plan{Amount: gotropipay.NewMoney(500, "EUR"), Interval: subscription.Monthly}
+	if _, err := s.Subscribe(context.Background(), "sub-1", "cust-1", plan, gotropipay.CardToken{Token: "tok"}, *now); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSchedulerSkipsSubscriptionCancelledAfterDue(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	charger := &fakeCharger{}
+	mem := subscription.NewMemoryStore()
+	var s *subscription.Scheduler
+	store := cancelAfterDue{mem, func() { s.Cancel(context.Background(), "sub-1") }}
+	s = newTestScheduler(t, charger, store, &now)
+
+	if n, err := s.RunOnce(context.Background()); err != nil || n != 0 {
+		t.Fatalf("RunOnce = %d, %v; want nothing charged", n, err)
+	}
+	sub, _ := mem.Get(context.Background(), "sub-1")
+	if len(charger.keys) != 0 || sub.Status != subscription.Cancelled {
+		t.Errorf("charged %d times, status %s", len(charger.keys), sub.Status)
+	}
+}
+
+func TestSchedulerCancelDuringCharge(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	charger := &fakeCharger{}
+	store := subscription.NewMemoryStore()
+	s := newTestScheduler(t, charger, store, &now)
+	charger.onCharge = func() {
+		if err := s.Cancel(context.Background(), "sub-1"); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := s.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	sub, _ := store.Get(context.Background(), "sub-1")
+	if sub.Status != subscription.Cancelled {
+		t.Errorf("status = %s, cancellation was overwritten", sub.Status)
+	}
+	if sub.PeriodsPaid != 1 || sub.LastCharge == nil || sub.LastCharge.State != gotropipay.MovementStateCompleted {
+		t.Errorf("in-flight charge not recorded: %+v", sub)
+	}
+}
+
+func TestSchedulerPendingAndTransportErrors(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	charger := &fakeCharger{errs: 1, pending: 1}
+	store := subscription.NewMemoryStore()
+	s := newTestScheduler(t, charger, store, &now)
+	ctx := context.Background()
+
+	// Transport error, then pending: both retry after an hour with the same key
+	for i, want := range []string{"connection reset", ""} {
+		if _, err := s.RunOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+		sub, _ := store.Get(ctx, "sub-1")
+		if sub.Status != subscription.Active || sub.PeriodsPaid != 0 || sub.Attempts != 0 ||
+			!sub.NextAttempt.Equal(now.Add(time.Hour)) || sub.LastCharge.Error != want {
+			t.Fatalf("step %d: %+v, last charge %+v", i, sub, sub.LastCharge)
+		}
+		if n, _ := s.RunOnce(ctx); n != 0 {
+			t.Fatalf("step %d: retried before ErrorRetry", i)
+		}
+		now = now.Add(time.Hour)
+	}
+	if sub, _ := store.Get(ctx, "sub-1"); sub.LastCharge.State != gotropipay.MovementStatePending {
+		t.Errorf("pending state not recorded: %+v", sub.LastCharge)
+	}
+
+	if _, err := s.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	sub, _ := store.Get(ctx, "sub-1")
+	if sub.PeriodsPaid != 1 {
+		t.Errorf("after pending settled: %+v", sub)
+	}
+	if charger.keys[0] != charger.keys[1] || charger.keys[1] != charger.keys[2] {
+		t.Errorf("keys %v: error and pending retries must reuse the key", charger.keys)
+	}
+}
+
+func TestMemoryStoreConditionalSave(t *testing.T) {
+	ctx := context.Background()
+	store := subscription.NewMemoryStore()
+	t0 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	sub := &subscription.Subscription{ID: "s", UpdatedAt: t0}
+	if err := store.Save(ctx, sub, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(ctx, sub, time.Time{}); !errors.Is(err, subscription.ErrConflict) {
+		t.Errorf("create over existing: err = %v", err)
+	}
+	sub.UpdatedAt = t0.Add(time.Second)
+	if err := store.Save(ctx, sub, t0.Add(-time.Second)); !errors.Is(err, subscription.ErrConflict) {
+		t.Errorf("stale save: err = %v", err)
+	}
+	if err := store.Save(ctx, sub, t0); err != nil {
+		t.Errorf("fresh save: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package sync
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS sync_state (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS movements (
+	id           TEXT PRIMARY KEY,
+	reference    TEXT NOT NULL DEFAULT '',
+	state        TEXT NOT NULL DEFAULT '',
+	currency     TEXT NOT NULL DEFAULT '',
+	amount       INTEGER NOT NULL DEFAULT 0,
+	created_unix INTEGER NOT NULL DEFAULT 0,
+	data         TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS movements_created ON movements (created_unix);
+CREATE INDEX IF NOT EXISTS movements_reference ON movements (reference);
+CREATE INDEX IF NOT EXISTS movements_state ON movements (state);
+CREATE TABLE IF NOT EXISTS payment_cards (
+	id        TEXT PRIMARY KEY,
+	reference TEXT NOT NULL DEFAULT '',
+	state     INTEGER NOT NULL DEFAULT 0,
+	data      TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS accounts (
+	id       INTEGER PRIMARY KEY,
+	currency TEXT NOT NULL DEFAULT '',
+	data     TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS deposit_accounts (
+	id             INTEGER PRIMARY KEY,
+	account_number TEXT NOT NULL DEFAULT '',
+	data           TEXT NOT NULL
+);
+`
+
+// SQLiteStore is a Store backed by a SQLite database.
+// The caller opens db with the SQLite driver of its choice
+// (e.g. modernc.org/sqlite or github.com/mattn/go-sqlite3).
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates the ledger tables if needed and returns the store
+func NewSQLiteStore(ctx context.Context, db *sql.DB) (*SQLiteStore, error) {
+	for _, stmt := range strings.Split(sqliteSchema, ";") {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("failed to migrate ledger: %w", err)
+		}
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Checkpoint(ctx context.Context, key string) (time.Time, error) {
+	var v string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM sync_state WHERE key = ?`, key).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, v)
+}
+
+func (s *SQLiteStore) SetCheckpoint(ctx context.Context, key string, t time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sync_state (key, value) VALUES (?, ?)
+		 ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
+		key, t.UTC().Format(time.RFC3339Nano))
+	return err
+}
+
+func (s *SQLiteStore) OldestPending(ctx context.Context) (time.Time, error) {
+	var unix sql.NullInt64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT MIN(created_unix) FROM movements WHERE lower(state) NOT IN (?, ?, ?)`,
+		string(gotropipay.MovementStateCompleted),
+		string(gotropipay.MovementStateFailed),
+		string(gotropipay.MovementStateCancelled),
+	).Scan(&unix)
+	if err != nil || !unix.Valid {
+		return time.Time{}, err
+	}
+	return time.Unix(unix.Int64, 0).UTC(), nil
+}
+
+func (s *SQLiteStore) GetMovement(ctx context.Context, id string) (*gotropipay.Movement, error) {
+	var m gotropipay.Movement
+	if err := s.getJSON(ctx, `SELECT data FROM movements WHERE id = ?`, id, &m); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (s *SQLiteStore) UpsertMovement(ctx context.Context, m gotropipay.Movement) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO movements (id, reference, state, currency, amount, created_unix, data)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET
+			reference = excluded.reference,
+			state = excluded.state,
+			currency = excluded.currency,
+			amount = excluded.amount,
+			created_unix = excluded.created_unix,
+			data = excluded.data`,
+		m.IDString(), m.Reference, m.State, m.Currency, m.Amount, parseTime(m.CreatedAt).Unix(), string(data))
+	return err
+}
+
+func (s *SQLiteStore) QueryMovements(ctx context.Context, q Query) ([]gotropipay.Movement, error) {
+	var where []string
+	var args []interface{}
+	if q.State != "" {
+		where = append(where, "lower(state) = lower(?)")
+		args = append(args, q.State)
+	}
+	if q.Currency != "" {
+		where = append(where, "currency = ?")
+		args = append(args, q.Currency)
+	}
+	if q.Reference != "" {
+		where = append(where, "reference = ?")
+		args = append(args, q.Reference)
+	}
+	if !q.From.IsZero() {
+		where = append(where, "created_unix >= ?")
+		args = append(args, q.From.Unix())
+	}
+	if !q.To.IsZero() {
+		where = append(where, "created_unix < ?")
+		args = append(args, q.To.Unix())
+	}
+
+	query := "SELECT data FROM movements"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_unix DESC, id"
+	if q.Limit > 0 || q.Offset > 0 {
+		limit := q.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, q.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []gotropipay.Movement
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var m gotropipay.Movement
+		if err := json.Unmarshal([]byte(data), &m); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLiteStore) GetPaymentCard(ctx context.Context, id string) (*gotropipay.PaymentCard, error) {
+	var c gotropipay.PaymentCard
+	if err := s.getJSON(ctx, `SELECT data FROM payment_cards WHERE id = ?`, id, &c); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *SQLiteStore) UpsertPaymentCard(ctx context.Context, c gotropipay.PaymentCard) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO payment_cards (id, reference, state, data) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET
+			reference = excluded.reference,
+			state = excluded.state,
+			data = excluded.data`,
+		c.ID, c.Reference, c.State, string(data))
+	return err
+}
+
+func (s *SQLiteStore) GetDepositAccount(ctx context.Context, id int) (*gotropipay.DepositAccount, error) {
+	var a gotropipay.DepositAccount
+	if err := s.getJSON(ctx, `SELECT data FROM deposit_accounts WHERE id = ?`, id, &a); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *SQLiteStore) UpsertDepositAccount(ctx context.Context, a gotropipay.DepositAccount) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO deposit_accounts (id, account_number, data) VALUES (?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET
+			account_number = excluded.account_number,
+			data = excluded.data`,
+		a.ID, a.AccountNumber, string(data))
+	return err
+}
+
+func (s *SQLiteStore) GetAccount(ctx context.Context, id int64) (*gotropipay.Account, error) {
+	var a gotropipay.Account
+	if err := s.getJSON(ctx, `SELECT data FROM accounts WHERE id = ?`, id, &a); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *SQLiteStore) UpsertAccount(ctx context.Context, a gotropipay.Account) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO accounts (id, currency, data) VALUES (?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET
+			currency = excluded.currency,
+			data = excluded.data`,
+		a.ID, a.Currency, string(data))
+	return err
+}
+
+func (s *SQLiteStore) getJSON(ctx context.Context, query string, arg interface{}, dst interface{}) error {
+	var data string
+	if err := s.db.QueryRowContext(ctx, query, arg).Scan(&data); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), dst)
+}
@@ -0,0 +1,34 @@
+package sync_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	tpsync "github.com/tropipay/gotropipay/sync"
+	_ "modernc.org/sqlite"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "ledger.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	st, err := tpsync.NewSQLiteStore(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, st)
+
+	// Reopening keeps the data and the schema is created idempotently
+	st, err = tpsync.NewSQLiteStore(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, err := st.GetMovement(context.Background(), "2"); err != nil || m == nil {
+		t.Errorf("after reopen: %+v, %v", m, err)
+	}
+}
@@ -0,0 +1,223 @@
+package sync
+
+import (
+	"context"
+	"sort"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// Store persists the ledger. SQLiteStore is the production implementation;
+// MemoryStore is convenient for tests.
+type Store interface {
+	// Checkpoint returns the high-water mark stored under key, or the zero time
+	Checkpoint(ctx context.Context, key string) (time.Time, error)
+	SetCheckpoint(ctx context.Context, key string, t time.Time) error
+	// OldestPending returns the creation time of the oldest non-terminal movement
+	OldestPending(ctx context.Context) (time.Time, error)
+
+	// GetMovement returns nil, nil when the movement is unknown
+	GetMovement(ctx context.Context, id string) (*gotropipay.Movement, error)
+	UpsertMovement(ctx context.Context, m gotropipay.Movement) error
+	QueryMovements(ctx context.Context, q Query) ([]gotropipay.Movement, error)
+
+	// GetPaymentCard returns nil, nil when the paylink is unknown
+	GetPaymentCard(ctx context.Context, id string) (*gotropipay.PaymentCard, error)
+	UpsertPaymentCard(ctx context.Context, c gotropipay.PaymentCard) error
+
+	// GetDepositAccount returns nil, nil when the beneficiary is unknown
+	GetDepositAccount(ctx context.Context, id int) (*gotropipay.DepositAccount, error)
+	UpsertDepositAccount(ctx context.Context, a gotropipay.DepositAccount) error
+
+	// GetAccount returns nil, nil when the account is unknown
+	GetAccount(ctx context.Context, id int64) (*gotropipay.Account, error)
+	UpsertAccount(ctx context.Context, a gotropipay.Account) error
+}
+
+// Checkpoint keys
+const (
+	CheckpointCreated   = "checkpoint"           // Latest createdAt seen
+	CheckpointCompleted = "completed_checkpoint" // Latest completedAt seen
+)
+
+// Query filters stored movements. Zero fields are ignored.
+type Query struct {
+	State     string
+	Currency  string
+	Reference string
+	From      time.Time // Inclusive, on createdAt
+	To        time.Time // Exclusive, on createdAt
+	Limit     int
+	Offset    int
+}
+
+func (q Query) matches(m gotropipay.Movement) bool {
+	if q.State != "" && !strings.EqualFold(q.State, m.State) {
+		return false
+	}
+	if q.Currency != "" && q.Currency != m.Currency {
+		return false
+	}
+	if q.Reference != "" && q.Reference != m.Reference {
+		return false
+	}
+	t := parseTime(m.CreatedAt)
+	if !q.From.IsZero() && t.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !t.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// MemoryStore is an in-memory Store
+type MemoryStore struct {
+	mu         gosync.Mutex
+	checkpoint map[string]time.Time
+	movements  map[string]gotropipay.Movement
+	cards      map[string]gotropipay.PaymentCard
+	deposits   map[int]gotropipay.DepositAccount
+	accounts   map[int64]gotropipay.Account
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		checkpoint: make(map[string]time.Time),
+		movements:  make(map[string]gotropipay.Movement),
+		cards:      make(map[string]gotropipay.PaymentCard),
+		deposits:   make(map[int]gotropipay.DepositAccount),
+		accounts:   make(map[int64]gotropipay.Account),
+	}
+}
+
+func (s *MemoryStore) Checkpoint(ctx context.Context, key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpoint[key], nil
+}
+
+func (s *MemoryStore) SetCheckpoint(ctx context.Context, key string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoint[key] = t
+	return nil
+}
+
+func (s *MemoryStore) OldestPending(ctx context.Context) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var oldest time.Time
+	for _, m := range s.movements {
+		if gotropipay.MovementState(m.State).IsTerminal() {
+			continue
+		}
+		if t := parseTime(m.CreatedAt); oldest.IsZero() || t.Before(oldest) {
+			oldest = t
+		}
+	}
+	return oldest, nil
+}
+
+func (s *MemoryStore) GetMovement(ctx context.Context, id string) (*gotropipay.Movement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.movements[id]
+	if !ok {
+		return nil, nil
+	}
+	return &m, nil
+}
+
+func (s *MemoryStore) UpsertMovement(ctx context.Context, m gotropipay.Movement) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.movements[m.IDString()] = m
+	return nil
+}
+
+func (s *MemoryStore) QueryMovements(ctx context.Context, q Query) ([]gotropipay.Movement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []gotropipay.Movement
+	for _, m := range s.movements {
+		if q.matches(m) {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ti, tj := parseTime(out[i].CreatedAt), parseTime(out[j].CreatedAt)
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return out[i].IDString() < out[j].IDString()
+	})
+
+	if q.Offset > 0 {
+		if q.Offset >= len(out) {
+			return nil, nil
+		}
+		out = out[q.Offset:]
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) GetPaymentCard(ctx context.Context, id string) (*gotropipay.PaymentCard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.cards[id]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (s *MemoryStore) UpsertPaymentCard(ctx context.Context, c gotropipay.PaymentCard) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cards[c.ID] = c
+	return nil
+}
+
+func (s *MemoryStore) GetDepositAccount(ctx context.Context, id int) (*gotropipay.DepositAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.deposits[id]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func (s *MemoryStore) UpsertDepositAccount(ctx context.Context, a gotropipay.DepositAccount) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deposits[a.ID] = a
+	return nil
+}
+
+func (s *MemoryStore) GetAccount(ctx context.Context, id int64) (*gotropipay.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func (s *MemoryStore) UpsertAccount(ctx context.Context, a gotropipay.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts[a.ID] = a
+	return nil
+}
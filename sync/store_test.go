@@ -0,0 +1,81 @@
+package sync_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+	tpsync "github.com/tropipay/gotropipay/sync"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, st tpsync.Store) {
+	t.Helper()
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if cp, err := st.Checkpoint(ctx, tpsync.CheckpointCreated); err != nil || !cp.IsZero() {
+		t.Fatalf("empty checkpoint = %v, %v", cp, err)
+	}
+	if err := st.SetCheckpoint(ctx, tpsync.CheckpointCreated, base); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetCheckpoint(ctx, tpsync.CheckpointCompleted, base.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if cp, _ := st.Checkpoint(ctx, tpsync.CheckpointCreated); !cp.Equal(base) {
+		t.Errorf("created checkpoint = %v, want %v", cp, base)
+	}
+	if cp, _ := st.Checkpoint(ctx, tpsync.CheckpointCompleted); !cp.Equal(base.Add(time.Hour)) {
+		t.Errorf("completed checkpoint = %v, want %v", cp, base.Add(time.Hour))
+	}
+
+	for i, m := range []gotropipay.Movement{
+		{ID: "1", State: "pending", Currency: "EUR", Amount: 100, Reference: "A", CreatedAt: base.Format(time.RFC3339)},
+		{ID: "2", State: "completed", Currency: "USD", Amount: 200, Reference: "B", CreatedAt: base.Add(time.Hour).Format(time.RFC3339)},
+		{ID: "1", State: "pending", Currency: "EUR", Amount: 150, Reference: "A", CreatedAt: base.Format(time.RFC3339)},
+	} {
+		if err := st.UpsertMovement(ctx, m); err != nil {
+			t.Fatalf("upsert %d: %v", i, err)
+		}
+	}
+	if m, err := st.GetMovement(ctx, "1"); err != nil || m == nil || m.Amount != 150 {
+		t.Errorf("GetMovement = %+v, %v", m, err)
+	}
+	if m, err := st.GetMovement(ctx, "missing"); err != nil || m != nil {
+		t.Errorf("GetMovement(missing) = %+v, %v", m, err)
+	}
+	if p, _ := st.OldestPending(ctx); !p.Equal(base) {
+		t.Errorf("OldestPending = %v, want %v", p, base)
+	}
+	if ms, _ := st.QueryMovements(ctx, tpsync.Query{Currency: "USD"}); len(ms) != 1 || ms[0].ID != "2" {
+		t.Errorf("query by currency = %+v", ms)
+	}
+	if ms, _ := st.QueryMovements(ctx, tpsync.Query{From: base.Add(time.Minute)}); len(ms) != 1 || ms[0].ID != "2" {
+		t.Errorf("query by date = %+v", ms)
+	}
+
+	if err := st.UpsertPaymentCard(ctx, gotropipay.PaymentCard{ID: "pc-1", Reference: "ORDER-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := st.GetPaymentCard(ctx, "pc-1"); err != nil || c == nil || c.Reference != "ORDER-1" {
+		t.Errorf("GetPaymentCard = %+v, %v", c, err)
+	}
+	if err := st.UpsertDepositAccount(ctx, gotropipay.DepositAccount{ID: 3, AccountNumber: "ES00"}); err != nil {
+		t.Fatal(err)
+	}
+	if a, err := st.GetDepositAccount(ctx, 3); err != nil || a == nil || a.AccountNumber != "ES00" {
+		t.Errorf("GetDepositAccount = %+v, %v", a, err)
+	}
+	if err := st.UpsertAccount(ctx, gotropipay.Account{ID: 4, Currency: "EUR"}); err != nil {
+		t.Fatal(err)
+	}
+	if a, err := st.GetAccount(ctx, 4); err != nil || a == nil || a.Currency != "EUR" {
+		t.Errorf("GetAccount = %+v, %v", a, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, tpsync.NewMemoryStore())
+}
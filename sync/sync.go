@@ -0,0 +1,304 @@
+// Package sync keeps a local ledger of movements, paylinks and beneficiaries
+// up to date by fetching only what changed since the last run.
+package sync
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// Source is the subset of the API used by the syncer. *gotropipay.Client implements it.
+type Source interface {
+	AllMovements(ctx context.Context, filter *gotropipay.MovementFilter) iter.Seq2[gotropipay.Movement, error]
+	ListPaymentCards(ctx context.Context) ([]gotropipay.PaymentCard, error)
+	ListDepositAccounts(ctx context.Context, limit, offset int, search string) ([]gotropipay.DepositAccount, error)
+	ListAccounts(ctx context.Context) ([]gotropipay.Account, error)
+}
+
+// ChangeType classifies a ledger change
+type ChangeType string
+
+const (
+	ChangeCreated      ChangeType = "created"
+	ChangeStateChanged ChangeType = "state_changed"
+	ChangeUpdated      ChangeType = "updated"
+)
+
+// Change describes a movement that was inserted or modified by a sync
+type Change struct {
+	Type     ChangeType
+	Movement gotropipay.Movement
+	Previous *gotropipay.Movement // nil for ChangeCreated
+}
+
+// Options configures a Syncer
+type Options struct {
+	// Overlap is subtracted from the checkpoint so late-arriving movements
+	// are not missed (default 10 minutes)
+	Overlap time.Duration
+	// MaxPendingAge bounds how far back pending movements are re-checked
+	// for state transitions (default 30 days)
+	MaxPendingAge time.Duration
+	// Since is the starting point when the store has no checkpoint yet
+	Since time.Time
+	// Changes, if set, receives every change; sends block until received or ctx is done
+	Changes chan<- Change
+	// SkipPaymentCards, SkipDepositAccounts and SkipAccounts disable syncing those tables
+	SkipPaymentCards    bool
+	SkipDepositAccounts bool
+	SkipAccounts        bool
+}
+
+// Result summarises a single sync run
+type Result struct {
+	Fetched         int
+	Created         int
+	StateChanged    int
+	Updated         int
+	PaymentCards    int
+	DepositAccounts int
+	Accounts        int
+	Checkpoint      time.Time // Latest createdAt seen
+	// CompletedCheckpoint is the latest completedAt seen
+	CompletedCheckpoint time.Time
+}
+
+// Syncer incrementally copies remote data into a Store
+type Syncer struct {
+	src  Source
+	st   Store
+	opts Options
+}
+
+// New creates a Syncer
+func New(src Source, st Store, opts Options) *Syncer {
+	if opts.Overlap <= 0 {
+		opts.Overlap = 10 * time.Minute
+	}
+	if opts.MaxPendingAge <= 0 {
+		opts.MaxPendingAge = 30 * 24 * time.Hour
+	}
+	return &Syncer{src: src, st: st, opts: opts}
+}
+
+// Run syncs every interval until ctx is cancelled
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sync(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync fetches movements created since the created checkpoint (minus the
+// overlap window, and reaching back to the oldest locally pending movement
+// within MaxPendingAge), then movements completed since the completed
+// checkpoint, so late transitions of older movements are caught too. Both
+// are upserted by ID. Paylinks, beneficiaries and accounts are refreshed in full.
+func (s *Syncer) Sync(ctx context.Context) (*Result, error) {
+	res := &Result{}
+
+	created, err := s.st.Checkpoint(ctx, CheckpointCreated)
+	if err != nil {
+		return nil, err
+	}
+	if created.IsZero() {
+		created = s.opts.Since
+	}
+	completed, err := s.st.Checkpoint(ctx, CheckpointCompleted)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Time{}
+	if !created.IsZero() {
+		from = created.Add(-s.opts.Overlap)
+		oldestPending, err := s.st.OldestPending(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Reach back for pending movements, but never past MaxPendingAge: one
+		// stale pending movement must not stop newer ones being re-checked
+		if !oldestPending.IsZero() {
+			if floor := maxTime(oldestPending, time.Now().Add(-s.opts.MaxPendingAge)); floor.Before(from) {
+				from = floor
+			}
+		}
+	}
+
+	res.Checkpoint, res.CompletedCheckpoint = created, completed
+	filter := &gotropipay.MovementFilter{}
+	if !from.IsZero() {
+		filter.CreatedAtFrom = from.UTC().Format(time.RFC3339)
+	}
+	if err := s.pull(ctx, filter, res); err != nil {
+		return nil, err
+	}
+	if !completed.IsZero() {
+		filter := &gotropipay.MovementFilter{CompletedAtFrom: completed.Add(-s.opts.Overlap).UTC().Format(time.RFC3339)}
+		if err := s.pull(ctx, filter, res); err != nil {
+			return nil, err
+		}
+	}
+	if res.CompletedCheckpoint.IsZero() {
+		res.CompletedCheckpoint = res.Checkpoint
+	}
+
+	if err := s.st.SetCheckpoint(ctx, CheckpointCreated, res.Checkpoint); err != nil {
+		return nil, err
+	}
+	if err := s.st.SetCheckpoint(ctx, CheckpointCompleted, res.CompletedCheckpoint); err != nil {
+		return nil, err
+	}
+
+	if !s.opts.SkipPaymentCards {
+		cards, err := s.src.ListPaymentCards(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cards {
+			if err := s.st.UpsertPaymentCard(ctx, c); err != nil {
+				return nil, err
+			}
+		}
+		res.PaymentCards = len(cards)
+	}
+
+	if !s.opts.SkipDepositAccounts {
+		const pageSize = 100
+		for offset := 0; ; offset += pageSize {
+			accounts, err := s.src.ListDepositAccounts(ctx, pageSize, offset, "")
+			if err != nil {
+				return nil, err
+			}
+			for _, a := range accounts {
+				if err := s.st.UpsertDepositAccount(ctx, a); err != nil {
+					return nil, err
+				}
+			}
+			res.DepositAccounts += len(accounts)
+			if len(accounts) < pageSize {
+				break
+			}
+		}
+	}
+
+	if !s.opts.SkipAccounts {
+		accounts, err := s.src.ListAccounts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accounts {
+			if err := s.st.UpsertAccount(ctx, a); err != nil {
+				return nil, err
+			}
+		}
+		res.Accounts = len(accounts)
+	}
+
+	return res, nil
+}
+
+// pull upserts every movement matching filter and advances the checkpoints in res
+func (s *Syncer) pull(ctx context.Context, filter *gotropipay.MovementFilter, res *Result) error {
+	for m, err := range s.src.AllMovements(ctx, filter) {
+		if err != nil {
+			return err
+		}
+		res.Fetched++
+
+		change, err := s.upsert(ctx, m)
+		if err != nil {
+			return err
+		}
+		if change != nil {
+			switch change.Type {
+			case ChangeCreated:
+				res.Created++
+			case ChangeStateChanged:
+				res.StateChanged++
+			case ChangeUpdated:
+				res.Updated++
+			}
+			if err := s.notify(ctx, *change); err != nil {
+				return err
+			}
+		}
+
+		res.Checkpoint = maxTime(res.Checkpoint, parseTime(m.CreatedAt))
+		res.CompletedCheckpoint = maxTime(res.CompletedCheckpoint, parseTime(m.CompletedAt))
+	}
+	return nil
+}
+
+func (s *Syncer) upsert(ctx context.Context, m gotropipay.Movement) (*Change, error) {
+	prev, err := s.st.GetMovement(ctx, m.IDString())
+	if err != nil {
+		return nil, err
+	}
+
+	var change *Change
+	switch {
+	case prev == nil:
+		change = &Change{Type: ChangeCreated, Movement: m}
+	case !strings.EqualFold(prev.State, m.State):
+		change = &Change{Type: ChangeStateChanged, Movement: m, Previous: prev}
+	case movementChanged(*prev, m):
+		change = &Change{Type: ChangeUpdated, Movement: m, Previous: prev}
+	default:
+		return nil, nil
+	}
+
+	if err := s.st.UpsertMovement(ctx, m); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (s *Syncer) notify(ctx context.Context, c Change) error {
+	if s.opts.Changes == nil {
+		return nil
+	}
+	select {
+	case s.opts.Changes <- c:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func movementChanged(a, b gotropipay.Movement) bool {
+	return a.Amount != b.Amount ||
+		a.Currency != b.Currency ||
+		a.Reference != b.Reference ||
+		a.CompletedAt != b.CompletedAt ||
+		a.BalanceBefore != b.BalanceBefore ||
+		a.BalanceAfter != b.BalanceAfter
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,153 @@
+package sync_test
+
+import (
+	"context"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+	tpsync "github.com/tropipay/gotropipay/sync"
+)
+
+type fakeSource struct {
+	movements []gotropipay.Movement
+	filters   []*gotropipay.MovementFilter
+}
+
+func (f *fakeSource) AllMovements(ctx context.Context, filter *gotropipay.MovementFilter) iter.Seq2[gotropipay.Movement, error] {
+	f.filters = append(f.filters, filter)
+	return func(yield func(gotropipay.Movement, error) bool) {
+		for _, m := range f.movements {
+			if filter.CreatedAtFrom != "" && m.CreatedAt < filter.CreatedAtFrom {
+				continue
+			}
+			if filter.CompletedAtFrom != "" && m.CompletedAt < filter.CompletedAtFrom {
+				continue
+			}
+			if !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (f *fakeSource) ListPaymentCards(ctx context.Context) ([]gotropipay.PaymentCard, error) {
+	return []gotropipay.PaymentCard{{ID: "pc-1", Reference: "ORDER-1"}}, nil
+}
+
+func (f *fakeSource) ListDepositAccounts(ctx context.Context, limit, offset int, search string) ([]gotropipay.DepositAccount, error) {
+	return nil, nil
+}
+
+func (f *fakeSource) ListAccounts(ctx context.Context) ([]gotropipay.Account, error) {
+	return []gotropipay.Account{{ID: 1, Currency: "EUR"}}, nil
+}
+
+func TestSyncDetectsStateTransitions(t *testing.T) {
+	ctx := context.Background()
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	src := &fakeSource{movements: []gotropipay.Movement{
+		{ID: "1", State: "pending", Amount: 100, CreatedAt: base.Format(time.RFC3339)},
+		{ID: "2", State: "completed", Amount: 200, CreatedAt: base.Add(30 * time.Minute).Format(time.RFC3339)},
+	}}
+	store := tpsync.NewMemoryStore()
+	changes := make(chan tpsync.Change, 10)
+	s := tpsync.New(src, store, tpsync.Options{Changes: changes, Overlap: time.Minute})
+
+	res, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 2 || res.PaymentCards != 1 || res.Accounts != 1 {
+		t.Fatalf("first sync: %+v", res)
+	}
+	<-changes
+	<-changes
+
+	// Second run: same data plus a transition, no duplicates
+	src.movements[0].State = "completed"
+	res, err = s.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 0 || res.StateChanged != 1 {
+		t.Fatalf("second sync: %+v", res)
+	}
+	c := <-changes
+	if c.Type != tpsync.ChangeStateChanged || c.Previous.State != "pending" || c.Movement.State != "completed" {
+		t.Fatalf("unexpected change: %+v", c)
+	}
+
+	// The window reaches back to the pending movement, before checkpoint-overlap
+	if got := src.filters[1].CreatedAtFrom; got != base.Format(time.RFC3339) {
+		t.Fatalf("second sync window started at %q", got)
+	}
+
+	stored, _ := store.QueryMovements(ctx, tpsync.Query{State: "completed"})
+	if len(stored) != 2 {
+		t.Fatalf("expected 2 completed movements, got %d", len(stored))
+	}
+}
+
+func TestSyncClampsPendingReachBack(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	stale := now.Add(-10 * 24 * time.Hour)
+	recent := now.Add(-2 * time.Hour)
+	src := &fakeSource{movements: []gotropipay.Movement{
+		{ID: "1", State: "pending", CreatedAt: stale.Format(time.RFC3339)},
+		{ID: "2", State: "pending", CreatedAt: recent.Format(time.RFC3339)},
+		{ID: "3", State: "completed", CreatedAt: now.Format(time.RFC3339)},
+	}}
+	s := tpsync.New(src, tpsync.NewMemoryStore(), tpsync.Options{MaxPendingAge: 24 * time.Hour})
+	if _, err := s.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The stale pending movement must not stop the recent one being re-checked
+	src.movements[1].State = "completed"
+	res, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StateChanged != 1 {
+		t.Fatalf("second sync: %+v", res)
+	}
+	from, _ := time.Parse(time.RFC3339, src.filters[1].CreatedAtFrom)
+	if from.After(recent) || !from.After(stale) {
+		t.Errorf("window started at %v, want between %v and %v", from, stale, recent)
+	}
+}
+
+func TestSyncCatchesLateCompletions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	old := now.Add(-60 * 24 * time.Hour)
+	src := &fakeSource{movements: []gotropipay.Movement{
+		{ID: "1", State: "pending", CreatedAt: old.Format(time.RFC3339)},
+		{ID: "2", State: "completed", CreatedAt: now.Add(-time.Hour).Format(time.RFC3339), CompletedAt: now.Add(-time.Hour).Format(time.RFC3339)},
+	}}
+	store := tpsync.NewMemoryStore()
+	s := tpsync.New(src, store, tpsync.Options{MaxPendingAge: 24 * time.Hour})
+	if _, err := s.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Created outside every createdAt window, but completed since the last run
+	src.movements[0].State = "completed"
+	src.movements[0].CompletedAt = now.Format(time.RFC3339)
+	res, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StateChanged != 1 {
+		t.Fatalf("second sync: %+v", res)
+	}
+	if got := src.filters[2].CompletedAtFrom; got == "" {
+		t.Error("second sync did not query by completedAt")
+	}
+	if cp, _ := store.Checkpoint(ctx, tpsync.CheckpointCompleted); !cp.Equal(now) {
+		t.Errorf("completed checkpoint = %v, want %v", cp, now)
+	}
+}
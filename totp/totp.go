@@ -0,0 +1,152 @@
+// Package totp generates and checks time-based one-time passwords (RFC 6238)
+// for the authenticator-app flavour of Tropipay two-factor authentication.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options configures code generation. The zero value matches authenticator
+// app defaults: 6 digits, 30-second period, one step of clock skew.
+type Options struct {
+	Digits int           // 6 to 8
+	Period time.Duration // Length of a time step, in whole seconds
+	Skew   int           // Steps accepted either side of now by Validate; negative disables
+}
+
+func (o Options) withDefaults() Options {
+	if o.Digits == 0 {
+		o.Digits = 6
+	}
+	if o.Period <= 0 {
+		o.Period = 30 * time.Second
+	}
+	if o.Skew == 0 {
+		o.Skew = 1
+	}
+	if o.Skew < 0 {
+		o.Skew = 0
+	}
+	return o
+}
+
+// check rejects options authenticator apps cannot reproduce
+func (o Options) check() error {
+	if o.Digits < 6 || o.Digits > 8 {
+		return fmt.Errorf("unsupported TOTP digits %d", o.Digits)
+	}
+	if o.Period < time.Second || o.Period%time.Second != 0 {
+		return fmt.Errorf("unsupported TOTP period %s: must be a whole number of seconds", o.Period)
+	}
+	return nil
+}
+
+// Generate returns the code for a base32 secret at time t
+func (o Options) Generate(secret string, t time.Time) (string, error) {
+	o = o.withDefaults()
+	if err := o.check(); err != nil {
+		return "", err
+	}
+	key, err := DecodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, o.counter(t), o.Digits), nil
+}
+
+// Validate reports whether code is valid for secret at time t, allowing Skew
+// steps of clock drift
+func (o Options) Validate(secret, code string, t time.Time) bool {
+	o = o.withDefaults()
+	if o.check() != nil {
+		return false
+	}
+	key, err := DecodeSecret(secret)
+	if err != nil || len(code) != o.Digits {
+		return false
+	}
+	counter := o.counter(t)
+	for d := -o.Skew; d <= o.Skew; d++ {
+		if int64(counter)+int64(d) < 0 {
+			continue
+		}
+		want := hotp(key, uint64(int64(counter)+int64(d)), o.Digits)
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyURI returns the otpauth:// URI that authenticator apps scan, labelled
+// "issuer:account"
+func (o Options) KeyURI(issuer, account, secret string) string {
+	o = o.withDefaults()
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+	q := url.Values{}
+	q.Set("secret", strings.ToUpper(strings.ReplaceAll(secret, " ", "")))
+	if issuer != "" {
+		q.Set("issuer", issuer)
+	}
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", strconv.Itoa(o.Digits))
+	q.Set("period", strconv.Itoa(int(o.Period/time.Second)))
+	return "otpauth://totp/" + url.PathEscape(label) + "?" + q.Encode()
+}
+
+func (o Options) counter(t time.Time) uint64 {
+	return uint64(t.Unix() / int64(o.Period/time.Second))
+}
+
+// Generate returns the 6-digit code for a base32 secret at time t, using the
+// 30-second period authenticator apps default to
+func Generate(secret string, t time.Time) (string, error) {
+	return Options{}.Generate(secret, t)
+}
+
+// Validate checks a code with the default options
+func Validate(secret, code string, t time.Time) bool {
+	return Options{}.Validate(secret, code, t)
+}
+
+// DecodeSecret decodes a base32 secret as shown by authenticator apps,
+// tolerating spaces, lower case and missing padding
+func DecodeSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	s = strings.TrimRight(s, "=")
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	return key, nil
+}
+
+// hotp implements RFC 4226 with HMAC-SHA1 and dynamic truncation
+func hotp(key []byte, counter uint64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, code%mod)
+}
@@ -0,0 +1,66 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay/totp"
+)
+
+// Test vectors from RFC 6238 appendix B (SHA-1, last 6 digits)
+func TestGenerateRFC6238(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for ts, want := range cases {
+		got, err := totp.Generate(secret, time.Unix(ts, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Generate(%d) = %s, want %s", ts, got, want)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	opts := totp.Options{Digits: 8}
+
+	// RFC 6238 appendix B, full 8 digits
+	if got, _ := opts.Generate(secret, time.Unix(1111111109, 0)); got != "07081804" {
+		t.Errorf("8-digit code = %s, want 07081804", got)
+	}
+
+	now := time.Unix(1111111109, 0)
+	late, _ := opts.Generate(secret, now.Add(-30*time.Second))
+	if !opts.Validate(secret, late, now) {
+		t.Error("code from the previous step should pass with default skew")
+	}
+	strict := totp.Options{Digits: 8, Skew: -1}
+	if strict.Validate(secret, late, now) {
+		t.Error("code from the previous step should fail without skew")
+	}
+
+	for _, bad := range []totp.Options{{Digits: 5}, {Digits: 9}, {Period: 500 * time.Millisecond}, {Period: 1500 * time.Millisecond}} {
+		if _, err := bad.Generate(secret, now); err == nil {
+			t.Errorf("%+v: expected error", bad)
+		}
+		if bad.Validate(secret, "123456", now) {
+			t.Errorf("%+v: validated", bad)
+		}
+	}
+
+	uri := totp.Options{}.KeyURI("Tropipay", "ops@example.com", "JBSW Y3DP")
+	want := "otpauth://totp/Tropipay:ops@example.com?algorithm=SHA1&digits=6&issuer=Tropipay&period=30&secret=JBSWY3DP"
+	if uri != want {
+		t.Errorf("KeyURI = %s", uri)
+	}
+}
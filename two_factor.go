@@ -0,0 +1,121 @@
+package gotropipay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tropipay/gotropipay/qr"
+	"github.com/tropipay/gotropipay/totp"
+)
+
+// ErrTOTPMismatch is returned by Enroll2FA when the confirmation code does not
+// match the secret, usually because of a typo or a wrong device clock
+var ErrTOTPMismatch = errors.New("TOTP code does not match the enrolment secret")
+
+// EnrollOptions configures Enroll2FA
+type EnrollOptions struct {
+	Issuer  string // Label shown in authenticator apps (default "Tropipay")
+	Account string // Defaults to the profile email
+	// TOTP may only change Skew: the server checks 6-digit, 30-second codes
+	TOTP totp.Options
+
+	// Confirm is shown the enrolment (QR code, URI) and returns the first code
+	// from the authenticator app. When nil the code is generated from the
+	// secret, which suits service accounts that keep the secret themselves.
+	Confirm func(ctx context.Context, e *Enrollment) (string, error)
+}
+
+// Enrollment is the result of enabling TOTP two-factor authentication. Store
+// Secret (or the URI) somewhere safe: it is the only way to regenerate codes
+// if the device is lost.
+type Enrollment struct {
+	Secret        string       `json:"secret"`
+	URI           string       `json:"uri"` // otpauth://totp/...
+	Issuer        string       `json:"issuer"`
+	Account       string       `json:"account"`
+	Options       totp.Options `json:"options"`
+	RecoveryCodes []string     `json:"recoveryCodes,omitempty"` // Only if the API returns them
+	EnabledAt     time.Time    `json:"enabledAt"`
+
+	QR *qr.Code `json:"-"` // Render with QR.PNG or QR.Terminal
+}
+
+// Enroll2FA fetches a new TOTP secret, builds the otpauth URI and QR code,
+// verifies the first code and enables TOTP on the account
+func (c *Client) Enroll2FA(ctx context.Context, opts *EnrollOptions) (*Enrollment, error) {
+	if opts == nil {
+		opts = &EnrollOptions{}
+	}
+
+	if d, p := opts.TOTP.Digits, opts.TOTP.Period; (d != 0 && d != 6) || (p != 0 && p != 30*time.Second) {
+		return nil, errors.New("unsupported TOTP options: the server only accepts 6-digit codes with a 30-second period")
+	}
+
+	secret, err := c.Get2FASecret(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := totp.DecodeSecret(secret.Secret); err != nil {
+		return nil, err
+	}
+
+	e := &Enrollment{
+		Secret:  secret.Secret,
+		Issuer:  opts.Issuer,
+		Account: opts.Account,
+		Options: opts.TOTP,
+	}
+	if e.Issuer == "" {
+		e.Issuer = "Tropipay"
+	}
+	if e.Account == "" {
+		user, err := c.GetUserProfile(ctx)
+		if err != nil {
+			return nil, err
+		}
+		e.Account = user.Email
+	}
+	e.URI = e.Options.KeyURI(e.Issuer, e.Account, e.Secret)
+	if e.QR, err = qr.Encode(e.URI, qr.Medium); err != nil {
+		return nil, err
+	}
+
+	var code string
+	if opts.Confirm != nil {
+		code, err = opts.Confirm(ctx, e)
+	} else {
+		code, err = e.Options.Generate(e.Secret, time.Now())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !e.Options.Validate(e.Secret, code, time.Now()) {
+		return nil, ErrTOTPMismatch
+	}
+
+	// Same payload as Configure2FA; recovery codes are decoded when present
+	req := Configure2FARequest{Enabled: true, Type: "totp", SecurityCode: code}
+	var resp struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	raw, err := c.send(ctx, "POST", "/users/2fa", nil, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable 2FA: %w", err)
+	}
+	if raw.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed to enable 2FA: API error: %s (status: %d) - %s", raw.URL, raw.StatusCode, string(raw.Body))
+	}
+	// The endpoint may answer with an empty body
+	if len(bytes.TrimSpace(raw.Body)) > 0 {
+		if err := json.Unmarshal(raw.Body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	e.RecoveryCodes = resp.RecoveryCodes
+	e.EnabledAt = time.Now().UTC()
+	return e, nil
+}
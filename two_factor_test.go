@@ -0,0 +1,57 @@
+package gotropipay_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tropipay/gotropipay"
+	"github.com/tropipay/gotropipay/totp"
+)
+
+func TestEnroll2FA(t *testing.T) {
+	var enabled gotropipay.Configure2FARequest
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/2fa/secret":
+			writeJSON(w, gotropipay.Get2FASecretResponse{Secret: "JBSWY3DPEHPK3PXP"})
+		case "/users/profile":
+			writeJSON(w, gotropipay.User{Email: "ops@example.com"})
+		case "/users/2fa":
+			_ = json.NewDecoder(r.Body).Decode(&enabled)
+			w.WriteHeader(http.StatusOK) // empty body
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	e, err := client.Enroll2FA(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !enabled.Enabled || enabled.Type != "totp" || len(enabled.SecurityCode) != 6 {
+		t.Errorf("Configure2FA payload = %+v", enabled)
+	}
+	if !strings.HasPrefix(e.URI, "otpauth://totp/Tropipay:ops@example.com?") || e.QR == nil {
+		t.Errorf("enrollment = %+v", e)
+	}
+	if _, err := e.QR.PNG(4); err != nil {
+		t.Error(err)
+	}
+
+	_, err = client.Enroll2FA(ctx, &gotropipay.EnrollOptions{
+		Confirm: func(context.Context, *gotropipay.Enrollment) (string, error) { return "000000", nil },
+	})
+	if !errors.Is(err, gotropipay.ErrTOTPMismatch) {
+		t.Errorf("err = %v, want ErrTOTPMismatch", err)
+	}
+
+	_, err = client.Enroll2FA(ctx, &gotropipay.EnrollOptions{TOTP: totp.Options{Digits: 8}})
+	if err == nil || !strings.Contains(err.Error(), "unsupported TOTP options") {
+		t.Errorf("err = %v, want unsupported TOTP options", err)
+	}
+}
@@ -0,0 +1,42 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BIC checks the structure of a SWIFT/BIC code (ISO 9362): 4-letter institution,
+// 2-letter country, 2-character location and an optional 3-character branch.
+// It returns the normalised code.
+func BIC(s string) (string, error) {
+	bic := strings.ToUpper(compact(s))
+	if len(bic) != 8 && len(bic) != 11 {
+		return "", fmt.Errorf("BIC %q: %w", s, ErrLength)
+	}
+	for i := 0; i < len(bic); i++ {
+		ch := bic[i]
+		var ok bool
+		if i < 6 {
+			ok = isUpper(ch)
+		} else {
+			ok = isUpper(ch) || isDigit(ch)
+		}
+		if !ok {
+			return "", fmt.Errorf("BIC %q: %w", s, ErrFormat)
+		}
+	}
+	// The letter O is not allowed as the second location character
+	if bic[7] == 'O' {
+		return "", fmt.Errorf("BIC %q: %w", s, ErrFormat)
+	}
+	return bic, nil
+}
+
+// BICCountry returns the ISO country code embedded in a BIC
+func BICCountry(bic string) string {
+	bic = strings.ToUpper(compact(bic))
+	if len(bic) < 6 {
+		return ""
+	}
+	return bic[4:6]
+}
@@ -0,0 +1,129 @@
+package validate
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// CardBrand is a card network detected from the BIN (leading digits)
+type CardBrand string
+
+const (
+	CardUnknown    CardBrand = ""
+	CardVisa       CardBrand = "visa"
+	CardMastercard CardBrand = "mastercard"
+	CardAmex       CardBrand = "amex"
+	CardDiscover   CardBrand = "discover"
+	CardDiners     CardBrand = "diners"
+	CardJCB        CardBrand = "jcb"
+	CardUnionPay   CardBrand = "unionpay"
+	CardMaestro    CardBrand = "maestro"
+)
+
+// binRange maps an inclusive prefix range of a given width to a brand
+type binRange struct {
+	lo, hi  int
+	width   int
+	brand   CardBrand
+	lengths []int
+}
+
+// binRanges is checked in order, so narrower ranges come first
+var binRanges = []binRange{
+	{34, 34, 2, CardAmex, []int{15}},
+	{37, 37, 2, CardAmex, []int{15}},
+	{300, 305, 3, CardDiners, []int{14, 16, 17, 18, 19}},
+	{36, 36, 2, CardDiners, []int{14, 15, 16, 17, 18, 19}},
+	{38, 39, 2, CardDiners, []int{14, 16, 17, 18, 19}},
+	{3528, 3589, 4, CardJCB, []int{16, 17, 18, 19}},
+	{2221, 2720, 4, CardMastercard, []int{16}},
+	{51, 55, 2, CardMastercard, []int{16}},
+	{6011, 6011, 4, CardDiscover, []int{16, 17, 18, 19}},
+	{644, 649, 3, CardDiscover, []int{16, 17, 18, 19}},
+	{65, 65, 2, CardDiscover, []int{16, 17, 18, 19}},
+	{62, 62, 2, CardUnionPay, []int{16, 17, 18, 19}},
+	{4, 4, 1, CardVisa, []int{13, 16, 19}},
+	{50, 50, 2, CardMaestro, []int{12, 13, 14, 15, 16, 17, 18, 19}},
+	{56, 69, 2, CardMaestro, []int{12, 13, 14, 15, 16, 17, 18, 19}},
+}
+
+// Luhn reports whether the digit string passes the mod-10 check
+func Luhn(digits string) bool {
+	if len(digits) < 2 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		if !isDigit(digits[i]) {
+			return false
+		}
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// DetectCardBrand identifies the network from the leading digits. Only the
+// prefix is needed, so it works on partially typed numbers.
+func DetectCardBrand(number string) CardBrand {
+	digits := compact(number)
+	for _, r := range binRanges {
+		if len(digits) < r.width {
+			continue
+		}
+		prefix, err := strconv.Atoi(digits[:r.width])
+		if err != nil {
+			return CardUnknown
+		}
+		if prefix >= r.lo && prefix <= r.hi {
+			return r.brand
+		}
+	}
+	return CardUnknown
+}
+
+// CardNumber checks digits, length for the detected brand and the Luhn
+// checksum. It returns the number without separators and its brand.
+func CardNumber(number string) (string, CardBrand, error) {
+	digits := compact(number)
+	for i := 0; i < len(digits); i++ {
+		if !isDigit(digits[i]) {
+			return "", CardUnknown, fmt.Errorf("card number: %w", ErrFormat)
+		}
+	}
+	if len(digits) < 12 || len(digits) > 19 {
+		return "", CardUnknown, fmt.Errorf("card number: %w", ErrLength)
+	}
+
+	brand := DetectCardBrand(digits)
+	for _, r := range binRanges {
+		if r.brand != brand || brand == CardUnknown {
+			continue
+		}
+		if !containsInt(r.lengths, len(digits)) {
+			return "", brand, fmt.Errorf("%s card number: %w", brand, ErrLength)
+		}
+		break
+	}
+	if !Luhn(digits) {
+		return "", brand, fmt.Errorf("card number: %w", ErrChecksum)
+	}
+	return digits, brand, nil
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ibanFormats holds the BBAN structure of each country in SWIFT registry
+// notation: a count followed by n (digits), a (upper-case letters) or c
+// (alphanumerics). The IBAN length is 4 plus the sum of the counts.
+var ibanFormats = map[string]string{
+	"AD": "4n4n12c", "AE": "3n16n", "AL": "8n16c", "AT": "5n11n", "AZ": "4a20c",
+	"BA": "3n3n8n2n", "BE": "3n7n2n", "BG": "4a4n2n8c", "BH": "4a14c", "BR": "8n5n10n1a1c",
+	"BY": "4c4n16c", "CH": "5n12c", "CR": "4n14n", "CY": "3n5n16c", "CZ": "4n6n10n",
+	"DE": "8n10n", "DK": "4n9n1n", "DO": "4c20n", "EE": "2n2n11n1n", "EG": "4n4n17n",
+	"ES": "4n4n1n1n10n", "FI": "3n11n", "FO": "4n9n1n", "FR": "5n5n11c2n", "GB": "4a6n8n",
+	"GE": "2a16n", "GI": "4a15c", "GL": "4n9n1n", "GR": "3n4n16c", "GT": "4c20c",
+	"HR": "7n10n", "HU": "3n4n1n15n1n", "IE": "4a6n8n", "IL": "3n3n13n", "IQ": "4a3n12n",
+	"IS": "4n2n6n10n", "IT": "1a5n5n12c", "JO": "4a4n18c", "KW": "4a22c", "KZ": "3n13c",
+	"LB": "4n20c", "LC": "4a24c", "LI": "5n12c", "LT": "5n11n", "LU": "3n13c",
+	"LV": "4a13c", "MC": "5n5n11c2n", "MD": "2c18c", "ME": "3n13n2n", "MK": "3n10c2n",
+	"MR": "5n5n11n2n", "MT": "4a5n18c", "MU": "4a2n2n12n3n3a", "NL": "4a10n", "NO": "4n6n1n",
+	"PK": "4a16c", "PL": "8n16n", "PS": "4a21c", "PT": "4n4n11n2n", "QA": "4a21c",
+	"RO": "4a16c", "RS": "3n13n2n", "SA": "2n18c", "SC": "4a2n2n16n3a", "SE": "3n16n1n",
+	"SI": "5n8n2n", "SK": "4n6n10n", "SM": "1a5n5n12c", "ST": "8n11n2n", "SV": "4a20n",
+	"TL": "3n14n2n", "TN": "2n3n13n2n", "TR": "5n1n16c", "UA": "6n19c", "VA": "3n15n",
+	"VG": "4a16n", "XK": "4n10n2n",
+}
+
+// IBAN checks country length and BBAN structure plus the ISO 7064 mod-97
+// checksum, and returns the IBAN in electronic format (no spaces, upper case).
+func IBAN(s string) (string, error) {
+	iban := strings.ToUpper(compact(s))
+	if len(iban) < 5 {
+		return "", fmt.Errorf("IBAN %q: %w", s, ErrLength)
+	}
+	if !isUpper(iban[0]) || !isUpper(iban[1]) || !isDigit(iban[2]) || !isDigit(iban[3]) {
+		return "", fmt.Errorf("IBAN %q: %w", s, ErrFormat)
+	}
+
+	country := iban[:2]
+	format, ok := ibanFormats[country]
+	if !ok {
+		return "", fmt.Errorf("IBAN %q: %w %s", s, ErrUnknownCountry, country)
+	}
+	if err := matchStructure(iban[4:], format); err != nil {
+		return "", fmt.Errorf("IBAN %q: %w", s, err)
+	}
+	if ibanMod97(iban) != 1 {
+		return "", fmt.Errorf("IBAN %q: %w", s, ErrChecksum)
+	}
+	return iban, nil
+}
+
+// IBANLength returns the expected IBAN length for a country, or 0 if unknown
+func IBANLength(country string) int {
+	format, ok := ibanFormats[strings.ToUpper(country)]
+	if !ok {
+		return 0
+	}
+	n := 4
+	for _, seg := range parseStructure(format) {
+		n += seg.count
+	}
+	return n
+}
+
+// LooksLikeIBAN reports whether s starts like an IBAN (two letters, two digits)
+// for a country that uses them, without checking the rest
+func LooksLikeIBAN(s string) bool {
+	s = strings.ToUpper(compact(s))
+	if len(s) < 4 || !isUpper(s[0]) || !isUpper(s[1]) || !isDigit(s[2]) || !isDigit(s[3]) {
+		return false
+	}
+	_, ok := ibanFormats[s[:2]]
+	return ok
+}
+
+func ibanMod97(iban string) int {
+	rearranged := iban[4:] + iban[:4]
+	rem := 0
+	for i := 0; i < len(rearranged); i++ {
+		ch := rearranged[i]
+		if isDigit(ch) {
+			rem = (rem*10 + int(ch-'0')) % 97
+		} else {
+			rem = (rem*100 + int(ch-'A') + 10) % 97
+		}
+	}
+	return rem
+}
+
+type segment struct {
+	count int
+	class byte
+}
+
+func parseStructure(format string) []segment {
+	var segs []segment
+	n := 0
+	for i := 0; i < len(format); i++ {
+		if isDigit(format[i]) {
+			n = n*10 + int(format[i]-'0')
+			continue
+		}
+		segs = append(segs, segment{count: n, class: format[i]})
+		n = 0
+	}
+	return segs
+}
+
+func matchStructure(bban, format string) error {
+	pos := 0
+	for _, seg := range parseStructure(format) {
+		if pos+seg.count > len(bban) {
+			return ErrLength
+		}
+		for _, ch := range []byte(bban[pos : pos+seg.count]) {
+			ok := false
+			switch seg.class {
+			case 'n':
+				ok = isDigit(ch)
+			case 'a':
+				ok = isUpper(ch)
+			case 'c':
+				ok = isDigit(ch) || isUpper(ch)
+			}
+			if !ok {
+				return ErrFormat
+			}
+		}
+		pos += seg.count
+	}
+	if pos != len(bban) {
+		return ErrLength
+	}
+	return nil
+}
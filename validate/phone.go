@@ -0,0 +1,70 @@
+package validate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// trunkPrefixes lists the national trunk prefixes that differ from the
+// common "0". An empty prefix means leading zeros are part of the number and
+// are dialled from abroad too.
+var trunkPrefixes = map[int]string{
+	1:   "1",  // North American Numbering Plan
+	7:   "8",  // Russia, Kazakhstan
+	30:  "",   // Greece
+	34:  "",   // Spain
+	36:  "06", // Hungary
+	39:  "",   // Italy
+	45:  "",   // Denmark
+	47:  "",   // Norway
+	351: "",   // Portugal
+	352: "",   // Luxembourg
+	376: "",   // Andorra
+	378: "",   // San Marino
+}
+
+// Phone checks a phone number against a country calling code (as in
+// CountryDestination.CallingCode) and returns it in E.164 form ("+34600123456").
+// International input ("+34 ...", "0034 ...") must carry that calling code;
+// anything else is treated as a national number, whose trunk prefix (usually
+// "0", but kept in Italy for example) is dropped.
+func Phone(number string, callingCode int) (string, error) {
+	if callingCode <= 0 {
+		return "", fmt.Errorf("phone %q: %w calling code %d", number, ErrUnknownCountry, callingCode)
+	}
+	s := compact(strings.NewReplacer("(", "", ")", "").Replace(number))
+	cc := strconv.Itoa(callingCode)
+
+	var national string
+	switch {
+	case strings.HasPrefix(s, "+"):
+		national = s[1:]
+	case strings.HasPrefix(s, "00"):
+		national = s[2:]
+	default:
+		trunk, ok := trunkPrefixes[callingCode]
+		if !ok {
+			trunk = "0"
+		}
+		if trunk != "" {
+			s = strings.TrimPrefix(s, trunk)
+		}
+		national = cc + s
+	}
+	for i := 0; i < len(national); i++ {
+		if !isDigit(national[i]) {
+			return "", fmt.Errorf("phone %q: %w", number, ErrFormat)
+		}
+	}
+	if !strings.HasPrefix(national, cc) {
+		return "", fmt.Errorf("phone %q: does not match calling code +%s: %w", number, cc, ErrFormat)
+	}
+
+	// E.164 allows at most 15 digits; subscriber numbers are at least 4
+	subscriber := len(national) - len(cc)
+	if len(national) > 15 || subscriber < 4 {
+		return "", fmt.Errorf("phone %q: %w", number, ErrLength)
+	}
+	return "+" + national, nil
+}
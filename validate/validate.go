@@ -0,0 +1,33 @@
+// Package validate checks account identifiers locally so malformed input can
+// be rejected before a round-trip to the API. It has no dependency on the SDK.
+package validate
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrFormat is returned when input contains unexpected characters or structure
+	ErrFormat = errors.New("invalid format")
+	// ErrLength is returned when input has the wrong number of characters
+	ErrLength = errors.New("invalid length")
+	// ErrChecksum is returned when check digits do not match
+	ErrChecksum = errors.New("invalid checksum")
+	// ErrUnknownCountry is returned when a country code is not supported
+	ErrUnknownCountry = errors.New("unknown country")
+)
+
+// compact strips spaces, dashes and dots that people use to group digits
+func compact(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '\t':
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+func isUpper(b byte) bool { return b >= 'A' && b <= 'Z' }
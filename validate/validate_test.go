@@ -0,0 +1,115 @@
+package validate_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tropipay/gotropipay/validate"
+)
+
+func TestIBAN(t *testing.T) {
+	valid := []string{
+		"ES91 2100 0418 4502 0005 1332",
+		"DE89370400440532013000",
+		"GB29NWBK60161331926819",
+		"fr1420041010050500013m02606",
+		"NL91ABNA0417164300",
+		"BE68539007547034",
+		"CH9300762011623852957",
+		"IT60X0542811101000000123456",
+	}
+	for _, s := range valid {
+		if _, err := validate.IBAN(s); err != nil {
+			t.Errorf("IBAN(%q): %v", s, err)
+		}
+	}
+
+	invalid := map[string]error{
+		"ES9121000418450200051333":  validate.ErrChecksum,
+		"ES912100041845020005133":   validate.ErrLength,
+		"DE8937040044053201300A":    validate.ErrFormat,
+		"ZZ89370400440532013000":    validate.ErrUnknownCountry,
+		"GB29NWBK6016133192681900X": validate.ErrLength,
+	}
+	for s, want := range invalid {
+		if _, err := validate.IBAN(s); !errors.Is(err, want) {
+			t.Errorf("IBAN(%q) = %v, want %v", s, err, want)
+		}
+	}
+
+	if n := validate.IBANLength("es"); n != 24 {
+		t.Errorf("IBANLength(ES) = %d, want 24", n)
+	}
+}
+
+func TestBIC(t *testing.T) {
+	for _, s := range []string{"DEUTDEFF", "deutdeff500", "CAIXESBBXXX"} {
+		if _, err := validate.BIC(s); err != nil {
+			t.Errorf("BIC(%q): %v", s, err)
+		}
+	}
+	for _, s := range []string{"DEUTDEF", "DEU1DEFF", "DEUTDEFFX"} {
+		if _, err := validate.BIC(s); err == nil {
+			t.Errorf("BIC(%q) should fail", s)
+		}
+	}
+}
+
+func TestCardNumber(t *testing.T) {
+	cases := map[string]validate.CardBrand{
+		"4111 1111 1111 1111": validate.CardVisa,
+		"5555555555554444":    validate.CardMastercard,
+		"2221000000000009":    validate.CardMastercard,
+		"378282246310005":     validate.CardAmex,
+		"6011111111111117":    validate.CardDiscover,
+		"3530111333300000":    validate.CardJCB,
+	}
+	for number, want := range cases {
+		_, brand, err := validate.CardNumber(number)
+		if err != nil || brand != want {
+			t.Errorf("CardNumber(%q) = %q, %v; want %q", number, brand, err, want)
+		}
+	}
+	if _, _, err := validate.CardNumber("4111111111111112"); !errors.Is(err, validate.ErrChecksum) {
+		t.Errorf("bad Luhn: %v", err)
+	}
+	if _, _, err := validate.CardNumber("37828224631000"); !errors.Is(err, validate.ErrLength) {
+		t.Errorf("short Amex: %v", err)
+	}
+}
+
+func TestPhone(t *testing.T) {
+	cases := map[string]string{
+		"+34 600 12 34 56": "+34600123456",
+		"0034600123456":    "+34600123456",
+		"600-123-456":      "+34600123456",
+	}
+	for in, want := range cases {
+		if got, err := validate.Phone(in, 34); err != nil || got != want {
+			t.Errorf("Phone(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := validate.Phone("+53 5 1234567", 34); !errors.Is(err, validate.ErrFormat) {
+		t.Errorf("wrong calling code: %v", err)
+	}
+	if _, err := validate.Phone("12", 34); !errors.Is(err, validate.ErrLength) {
+		t.Errorf("too short: %v", err)
+	}
+
+	// The trunk prefix depends on the country
+	for _, c := range []struct {
+		in   string
+		cc   int
+		want string
+	}{
+		{"06 1234 5678", 39, "+390612345678"}, // Italy keeps the 0
+		{"+39 06 1234 5678", 39, "+390612345678"},
+		{"030 1234567", 49, "+49301234567"}, // Germany drops it
+		{"8 912 345 67 89", 7, "+79123456789"},
+		{"06 1 234 5678", 36, "+3612345678"},
+	} {
+		if got, err := validate.Phone(c.in, c.cc); err != nil || got != c.want {
+			t.Errorf("Phone(%q, %d) = %q, %v; want %q", c.in, c.cc, got, err, c.want)
+		}
+	}
+}
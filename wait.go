@@ -0,0 +1,187 @@
+package gotropipay
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrPaymentCardExpired is returned by WaitForPayment when the paylink expires unpaid
+var ErrPaymentCardExpired = errors.New("payment card expired before being paid")
+
+// WaitOptions configures WaitForPayment. A nil *WaitOptions uses the defaults.
+type WaitOptions struct {
+	InitialInterval time.Duration // First poll delay (default 2s)
+	MaxInterval     time.Duration // Backoff ceiling (default 30s)
+	Multiplier      float64       // Backoff growth factor (default 1.5)
+
+	// Webhook, if set, short-circuits polling when a successful notification
+	// for the paylink's reference arrives first
+	Webhook *WebhookListener
+
+	// OnProgress, if set, is called after every poll
+	OnProgress func(WaitProgress)
+}
+
+// WaitProgress describes a single polling attempt
+type WaitProgress struct {
+	Attempt  int
+	Card     *PaymentCard
+	Movement *Movement // Latest movement seen, if any
+	NextPoll time.Duration
+}
+
+// PaymentResult is the terminal outcome of a paylink
+type PaymentResult struct {
+	State    MovementState
+	Card     *PaymentCard
+	Movement *Movement
+}
+
+func (o *WaitOptions) withDefaults() WaitOptions {
+	out := WaitOptions{}
+	if o != nil {
+		out = *o
+	}
+	if out.InitialInterval <= 0 {
+		out.InitialInterval = 2 * time.Second
+	}
+	if out.MaxInterval <= 0 {
+		out.MaxInterval = 30 * time.Second
+	}
+	if out.Multiplier < 1 {
+		out.Multiplier = 1.5
+	}
+	return out
+}
+
+// WaitForPayment polls a paylink until it is paid or closes. Every poll
+// re-reads the paylink: a paid, cancelled or expired State ends the wait, as
+// does a completed movement for its reference. A failed movement does not,
+// since the customer may retry while the paylink is open. The deadline is
+// taken from ctx; the matched movement, if any, is returned alongside the state.
+func (c *Client) WaitForPayment(ctx context.Context, id string, opts *WaitOptions) (*PaymentResult, error) {
+	o := opts.withDefaults()
+
+	card, err := c.GetPaymentCard(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var events chan WebhookEvent
+	if o.Webhook != nil {
+		events = make(chan WebhookEvent, 1)
+		unsubscribe := o.Webhook.Subscribe(func(e WebhookEvent) {
+			// A declined attempt (KO) leaves the paylink open for a retry, so
+			// only a success ends the wait early; polling sees it close
+			if e.Data.Reference != card.Reference || !e.Succeeded() {
+				return
+			}
+			select {
+			case events <- e:
+			default:
+			}
+		})
+		defer unsubscribe()
+	}
+
+	interval := o.InitialInterval
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if card, err = c.GetPaymentCard(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		mov, err := c.findPaymentMovement(ctx, card.Reference, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case card.State == PaymentCardStatePaid:
+			return &PaymentResult{State: MovementStateCompleted, Card: card, Movement: mov}, nil
+		case card.State == PaymentCardStateCancelled:
+			return &PaymentResult{State: MovementStateCancelled, Card: card, Movement: mov}, nil
+		case mov != nil && MovementState(strings.ToLower(mov.State)) == MovementStateCompleted:
+			return &PaymentResult{State: MovementStateCompleted, Card: card, Movement: mov}, nil
+		case card.State == PaymentCardStateExpired || paymentCardExpired(card, time.Now()):
+			return &PaymentResult{Card: card, Movement: mov}, ErrPaymentCardExpired
+		}
+
+		if o.OnProgress != nil {
+			o.OnProgress(WaitProgress{Attempt: attempt, Card: card, Movement: mov, NextPoll: interval})
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case e := <-events:
+			timer.Stop()
+			m := e.Movement()
+			return &PaymentResult{State: MovementState(strings.ToLower(m.State)), Card: card, Movement: &m}, nil
+		case <-timer.C:
+		}
+
+		interval = time.Duration(float64(interval) * o.Multiplier)
+		if interval > o.MaxInterval {
+			interval = o.MaxInterval
+		}
+	}
+}
+
+// findPaymentMovement returns the most relevant movement for a reference:
+// a completed one if any (a failed attempt may precede a successful retry),
+// then any other terminal one, then the latest pending one. A non-zero since
+// ignores movements not known to be created at or after it.
+func (c *Client) findPaymentMovement(ctx context.Context, reference string, since time.Time) (*Movement, error) {
+	filter := &MovementFilter{Reference: reference}
+	if !since.IsZero() {
+		filter.CreatedAtFrom = since.UTC().Format(time.RFC3339)
+	}
+	resp, err := c.ListMovements(ctx, 10, 0, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var terminal, latest *Movement
+	for i := range resp.Items {
+		m := &resp.Items[i]
+		if m.Reference != reference {
+			continue
+		}
+		if !since.IsZero() {
+			created, err := time.Parse(time.RFC3339, m.CreatedAt)
+			if err != nil || created.Before(since) {
+				continue
+			}
+		}
+		switch {
+		case MovementState(strings.ToLower(m.State)) == MovementStateCompleted:
+			return m, nil
+		case MovementState(m.State).IsTerminal():
+			if terminal == nil {
+				terminal = m
+			}
+		default:
+			latest = m
+		}
+	}
+	if terminal != nil {
+		return terminal, nil
+	}
+	return latest, nil
+}
+
+func paymentCardExpired(card *PaymentCard, now time.Time) bool {
+	if card.ExpirationDate == "" {
+		return false
+	}
+	exp, err := time.Parse(time.RFC3339, card.ExpirationDate)
+	if err != nil {
+		return false
+	}
+	return now.After(exp)
+}
@@ -0,0 +1,155 @@
+package gotropipay_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+func TestWaitForPaymentPolls(t *testing.T) {
+	var polls atomic.Int32
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/paymentcards/pc-1":
+			writeJSON(w, gotropipay.PaymentCard{ID: "pc-1", Reference: "ORDER-1"})
+		case "/movements/":
+			state := "pending"
+			if polls.Add(1) >= 3 {
+				state = "completed"
+			}
+			writeJSON(w, gotropipay.ListMovementsResponse{Items: []gotropipay.Movement{
+				{ID: 7, Reference: "ORDER-1", State: state, Amount: 1500, Currency: "EUR"},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var progress int
+	res, err := client.WaitForPayment(ctx, "pc-1", &gotropipay.WaitOptions{
+		InitialInterval: time.Millisecond,
+		OnProgress:      func(gotropipay.WaitProgress) { progress++ },
+	})
+	if err != nil {
+		t.Fatalf("WaitForPayment: %v", err)
+	}
+	if res.State != gotropipay.MovementStateCompleted || res.Movement.Amount != 1500 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if progress != 2 {
+		t.Fatalf("expected 2 progress callbacks, got %d", progress)
+	}
+}
+
+func TestWaitForPaymentWebhookShortCircuit(t *testing.T) {
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/paymentcards/pc-1":
+			writeJSON(w, gotropipay.PaymentCard{ID: "pc-1", Reference: "ORDER-1"})
+		case "/movements/":
+			writeJSON(w, gotropipay.ListMovementsResponse{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	listener := client.NewWebhookListener()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		listener.Dispatch(gotropipay.WebhookEvent{Status: "OK", Data: gotropipay.WebhookData{Reference: "ORDER-1", Amount: 1500}})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.WaitForPayment(ctx, "pc-1", &gotropipay.WaitOptions{InitialInterval: time.Hour, Webhook: listener})
+	if err != nil {
+		t.Fatalf("WaitForPayment: %v", err)
+	}
+	if res.State != gotropipay.MovementStateCompleted {
+		t.Fatalf("expected completed, got %q", res.State)
+	}
+}
+
+func TestWaitForPaymentReadsPaylinkState(t *testing.T) {
+	for _, tc := range []struct {
+		state   int
+		want    gotropipay.MovementState
+		wantErr error
+	}{
+		{gotropipay.PaymentCardStatePaid, gotropipay.MovementStateCompleted, nil},
+		{gotropipay.PaymentCardStateCancelled, gotropipay.MovementStateCancelled, nil},
+		{gotropipay.PaymentCardStateExpired, "", gotropipay.ErrPaymentCardExpired},
+	} {
+		var reads atomic.Int32
+		client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/paymentcards/pc-1":
+				// The paylink changes state after the first poll; no movement is listed yet
+				card := gotropipay.PaymentCard{ID: "pc-1", Reference: "ORDER-1", State: gotropipay.PaymentCardStateActive}
+				if reads.Add(1) > 1 {
+					card.State = tc.state
+				}
+				writeJSON(w, card)
+			case "/movements/":
+				writeJSON(w, gotropipay.ListMovementsResponse{})
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		res, err := client.WaitForPayment(ctx, "pc-1", &gotropipay.WaitOptions{InitialInterval: time.Millisecond})
+		cancel()
+		if !errors.Is(err, tc.wantErr) || res == nil || res.State != tc.want {
+			t.Errorf("state %d: res %+v, err %v", tc.state, res, err)
+		}
+		if reads.Load() != 2 {
+			t.Errorf("state %d: paylink read %d times, want 2", tc.state, reads.Load())
+		}
+	}
+}
+
+func TestWaitForPaymentOutlastsDeclinedAttempt(t *testing.T) {
+	var polls atomic.Int32
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/paymentcards/pc-1":
+			writeJSON(w, gotropipay.PaymentCard{ID: "pc-1", Reference: "ORDER-1", State: gotropipay.PaymentCardStateActive})
+		case "/movements/":
+			// The first card is declined; the customer retries and pays
+			items := []gotropipay.Movement{{ID: 1, Reference: "ORDER-1", State: "Failed"}}
+			if polls.Add(1) >= 3 {
+				items = append(items, gotropipay.Movement{ID: 2, Reference: "ORDER-1", State: "Completed"})
+			}
+			writeJSON(w, gotropipay.ListMovementsResponse{Items: items})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	listener := client.NewWebhookListener()
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		listener.Dispatch(gotropipay.WebhookEvent{Status: "KO", Data: gotropipay.WebhookData{Reference: "ORDER-1"}})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.WaitForPayment(ctx, "pc-1", &gotropipay.WaitOptions{InitialInterval: 10 * time.Millisecond, Webhook: listener})
+	if err != nil {
+		t.Fatalf("WaitForPayment: %v", err)
+	}
+	if res.State != gotropipay.MovementStateCompleted || res.Movement.IDString() != "2" {
+		t.Fatalf("res = %+v, want completed movement 2", res)
+	}
+}
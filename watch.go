@@ -0,0 +1,391 @@
+package gotropipay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MovementEventType classifies a watched movement change
+type MovementEventType string
+
+const (
+	MovementCreated      MovementEventType = "created"
+	MovementStateChanged MovementEventType = "state_changed"
+)
+
+// MovementEvent is emitted by WatchMovements
+type MovementEvent struct {
+	Type     MovementEventType
+	Movement Movement
+	Previous *Movement // Last version seen; set for MovementStateChanged
+}
+
+// WatchCursor is the resumable position of a watcher. It is JSON-serializable.
+type WatchCursor struct {
+	Since time.Time      `json:"since"` // Highest createdAt observed
+	Seen  []SeenMovement `json:"seen"`  // Most recently tracked movements, oldest first
+	// Evicted is the highest createdAt dropped from Seen to honour MaxTracked
+	Evicted time.Time `json:"evicted,omitempty"`
+}
+
+// SeenMovement is the dedupe record kept for each tracked movement
+type SeenMovement struct {
+	ID        string    `json:"id"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"createdAt"`
+	Movement  *Movement `json:"movement,omitempty"` // Last version seen, reported as Previous on a change
+}
+
+// CursorStore persists a WatchCursor between runs
+type CursorStore interface {
+	LoadCursor(ctx context.Context) (*WatchCursor, error) // nil, nil when none is stored
+	SaveCursor(ctx context.Context, cursor *WatchCursor) error
+}
+
+// FileCursorStore stores the cursor as JSON in a file
+type FileCursorStore struct {
+	Path string
+}
+
+// LoadCursor reads the cursor file, returning nil if it does not exist
+func (s FileCursorStore) LoadCursor(ctx context.Context) (*WatchCursor, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cursor WatchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// SaveCursor atomically replaces the cursor file
+func (s FileCursorStore) SaveCursor(ctx context.Context, cursor *WatchCursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".cursor-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// WatchOptions configures WatchMovements. A nil *WatchOptions uses the defaults.
+type WatchOptions struct {
+	MinInterval time.Duration // Poll interval after activity (default 2s)
+	MaxInterval time.Duration // Poll interval ceiling when idle (default 1m, and never below MinInterval)
+
+	// Overlap is re-scanned behind the cursor to catch late arrivals (default 5m)
+	Overlap time.Duration
+	// MaxPendingAge bounds how far back pending movements are re-polled (default 7 days)
+	MaxPendingAge time.Duration
+	// MaxTracked bounds the dedupe memory (default 10000 movements). Terminal
+	// movements are forgotten first; untracked movements created before the
+	// newest forgotten one are not reported as created again.
+	MaxTracked int
+
+	// Cursor, if set, is loaded on start and saved after every poll
+	Cursor CursorStore
+	// Since is the starting point when no cursor is stored (default: now)
+	Since time.Time
+
+	// Webhook, if set, feeds notifications into the same stream between polls
+	Webhook *WebhookListener
+
+	// OnError is called for poll and cursor errors; watching continues with backoff
+	OnError func(error)
+}
+
+func (o *WatchOptions) withDefaults() WatchOptions {
+	out := WatchOptions{}
+	if o != nil {
+		out = *o
+	}
+	if out.MinInterval <= 0 {
+		out.MinInterval = 2 * time.Second
+	}
+	if out.MaxInterval <= 0 {
+		out.MaxInterval = max(time.Minute, out.MinInterval)
+	}
+	if out.MaxInterval < out.MinInterval {
+		out.MaxInterval = out.MinInterval
+	}
+	if out.Overlap <= 0 {
+		out.Overlap = 5 * time.Minute
+	}
+	if out.MaxPendingAge <= 0 {
+		out.MaxPendingAge = 7 * 24 * time.Hour
+	}
+	if out.MaxTracked <= 0 {
+		out.MaxTracked = 10000
+	}
+	if out.Since.IsZero() {
+		out.Since = time.Now()
+	}
+	return out
+}
+
+// WatchMovements emits an event whenever a movement matching filter appears
+// or changes state. It polls SearchMovements adaptively, optionally merges
+// webhook notifications, and closes the channel when ctx is done.
+func (c *Client) WatchMovements(ctx context.Context, filter *MovementFilter, opts *WatchOptions) <-chan MovementEvent {
+	o := opts.withDefaults()
+	out := make(chan MovementEvent)
+
+	w := &watcher{client: c, filter: filter, opts: o, out: out}
+	go w.run(ctx)
+
+	return out
+}
+
+type watcher struct {
+	client *Client
+	filter *MovementFilter
+	opts   WatchOptions
+	out    chan MovementEvent
+
+	since   time.Time
+	evicted time.Time
+	seen    map[string]SeenMovement
+	order   []string
+}
+
+func (w *watcher) run(ctx context.Context) {
+	defer close(w.out)
+
+	w.seen = make(map[string]SeenMovement)
+	w.since = w.opts.Since
+	if w.opts.Cursor != nil {
+		cursor, err := w.opts.Cursor.LoadCursor(ctx)
+		if err != nil {
+			w.reportError(err)
+		} else if cursor != nil {
+			w.restore(cursor)
+		}
+	}
+
+	var hooks chan WebhookEvent
+	if w.opts.Webhook != nil {
+		hooks = make(chan WebhookEvent, 64)
+		unsubscribe := w.opts.Webhook.Subscribe(func(e WebhookEvent) {
+			select {
+			case hooks <- e:
+			default: // the next poll catches up
+			}
+		})
+		defer unsubscribe()
+	}
+
+	interval := w.opts.MinInterval
+	for {
+		emitted, err := w.poll(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			w.reportError(err)
+			interval *= 2
+		case emitted > 0:
+			interval = w.opts.MinInterval
+		default:
+			interval = interval * 3 / 2
+		}
+		if interval > w.opts.MaxInterval {
+			interval = w.opts.MaxInterval
+		}
+
+		if err == nil && w.opts.Cursor != nil {
+			if err := w.opts.Cursor.SaveCursor(ctx, w.cursor()); err != nil {
+				w.reportError(err)
+			}
+		}
+
+		timer := time.NewTimer(interval)
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case e := <-hooks:
+				m := e.Movement()
+				if !w.filter.matches(m) {
+					continue
+				}
+				emitted, err := w.observe(ctx, m)
+				if err != nil {
+					timer.Stop()
+					return
+				}
+				if emitted && w.opts.Cursor != nil {
+					if err := w.opts.Cursor.SaveCursor(ctx, w.cursor()); err != nil {
+						w.reportError(err)
+					}
+				}
+			case <-timer.C:
+				break wait
+			}
+		}
+	}
+}
+
+// poll scans from the cursor (minus overlap, or back to the oldest pending
+// movement within MaxPendingAge) and returns the number of events emitted
+func (w *watcher) poll(ctx context.Context) (int, error) {
+	from := w.since.Add(-w.opts.Overlap)
+	if p := w.oldestPending(); !p.IsZero() {
+		if floor := time.Now().Add(-w.opts.MaxPendingAge); p.Before(floor) {
+			p = floor
+		}
+		if p.Before(from) {
+			from = p
+		}
+	}
+
+	f := MovementFilter{}
+	if w.filter != nil {
+		f = *w.filter
+	}
+	f.CreatedAtFrom = from.UTC().Format(time.RFC3339)
+
+	const pageSize = 100
+	emitted := 0
+	for offset := 0; ; offset += pageSize {
+		resp, err := w.client.SearchMovements(ctx, &f, pageSize, offset)
+		if err != nil {
+			return emitted, err
+		}
+		for _, m := range resp.Items {
+			ok, err := w.observe(ctx, m)
+			if err != nil {
+				return emitted, err
+			}
+			if ok {
+				emitted++
+			}
+		}
+		if len(resp.Items) < pageSize {
+			return emitted, nil
+		}
+	}
+}
+
+// observe records m and emits an event if it is new or changed state.
+// It reports whether an event was emitted.
+func (w *watcher) observe(ctx context.Context, m Movement) (bool, error) {
+	id := m.IDString()
+	created, _ := time.Parse(time.RFC3339, m.CreatedAt)
+	if created.After(w.since) {
+		w.since = created
+	}
+
+	prev, known := w.seen[id]
+	if known && strings.EqualFold(prev.State, m.State) {
+		return false, nil
+	}
+	last := m
+	w.track(SeenMovement{ID: id, State: m.State, CreatedAt: created, Movement: &last})
+	if !known && !w.evicted.IsZero() && !created.IsZero() && !created.After(w.evicted) {
+		// Forgotten to honour MaxTracked, not new
+		return false, nil
+	}
+
+	ev := MovementEvent{Type: MovementCreated, Movement: m}
+	if known {
+		ev = MovementEvent{Type: MovementStateChanged, Movement: m, Previous: prev.Movement}
+	}
+
+	select {
+	case w.out <- ev:
+		return true, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func (w *watcher) track(s SeenMovement) {
+	if _, ok := w.seen[s.ID]; !ok {
+		w.order = append(w.order, s.ID)
+	}
+	w.seen[s.ID] = s
+
+	for len(w.order) > w.opts.MaxTracked {
+		w.evict()
+	}
+}
+
+// evict forgets the oldest terminal movement, or the oldest movement if none
+// is terminal, and raises the eviction horizon to its createdAt
+func (w *watcher) evict() {
+	i := 0
+	for j, id := range w.order {
+		if MovementState(w.seen[id].State).IsTerminal() {
+			i = j
+			break
+		}
+	}
+	s := w.seen[w.order[i]]
+	if s.CreatedAt.After(w.evicted) {
+		w.evicted = s.CreatedAt
+	}
+	delete(w.seen, s.ID)
+	w.order = append(w.order[:i], w.order[i+1:]...)
+}
+
+func (w *watcher) oldestPending() time.Time {
+	var oldest time.Time
+	for _, s := range w.seen {
+		if MovementState(s.State).IsTerminal() {
+			continue
+		}
+		if oldest.IsZero() || s.CreatedAt.Before(oldest) {
+			oldest = s.CreatedAt
+		}
+	}
+	return oldest
+}
+
+func (w *watcher) cursor() *WatchCursor {
+	seen := make([]SeenMovement, 0, len(w.order))
+	for _, id := range w.order {
+		seen = append(seen, w.seen[id])
+	}
+	return &WatchCursor{Since: w.since, Seen: seen, Evicted: w.evicted}
+}
+
+func (w *watcher) restore(c *WatchCursor) {
+	if !c.Since.IsZero() {
+		w.since = c.Since
+	}
+	w.evicted = c.Evicted
+	for _, s := range c.Seen {
+		w.track(s)
+	}
+}
+
+func (w *watcher) reportError(err error) {
+	if w.opts.OnError != nil {
+		w.opts.OnError(err)
+	}
+}
@@ -0,0 +1,190 @@
+package gotropipay_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tropipay/gotropipay"
+)
+
+// fakeBusiness serves /movements/business from a mutable movement list
+type fakeBusiness struct {
+	mu    sync.Mutex
+	items []map[string]interface{}
+}
+
+func (f *fakeBusiness) set(id, state string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, it := range f.items {
+		if it["id"] == id {
+			it["state"] = state
+			return
+		}
+	}
+	f.items = append(f.items, map[string]interface{}{
+		"id": id, "state": state, "createdAt": time.Now().UTC().Format(time.RFC3339),
+		"amount": map[string]interface{}{"value": 100, "currency": "EUR"},
+	})
+}
+
+func (f *fakeBusiness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	items := make([]map[string]interface{}, len(f.items))
+	for i, it := range f.items {
+		cp := make(map[string]interface{}, len(it))
+		for k, v := range it {
+			cp[k] = v
+		}
+		items[i] = cp
+	}
+	writeJSON(w, map[string]interface{}{
+		"data": map[string]interface{}{
+			"movements": map[string]interface{}{"items": items, "totalCount": len(items)},
+		},
+	})
+}
+
+func nextEvent(t *testing.T, ch <-chan gotropipay.MovementEvent) gotropipay.MovementEvent {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return gotropipay.MovementEvent{}
+	}
+}
+
+func TestWatchMovements(t *testing.T) {
+	fake := &fakeBusiness{}
+	fake.set("m1", "pending")
+	client := newFakeClient(t, fake)
+
+	cursor := gotropipay.FileCursorStore{Path: filepath.Join(t.TempDir(), "cursor.json")}
+	opts := &gotropipay.WatchOptions{
+		MinInterval: time.Millisecond,
+		MaxInterval: 5 * time.Millisecond,
+		Since:       time.Now().Add(-time.Hour),
+		Cursor:      cursor,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := client.WatchMovements(ctx, nil, opts)
+
+	if ev := nextEvent(t, events); ev.Type != gotropipay.MovementCreated || ev.Movement.IDString() != "m1" {
+		t.Fatalf("unexpected first event: %+v", ev)
+	}
+
+	fake.set("m1", "completed")
+	ev := nextEvent(t, events)
+	if ev.Type != gotropipay.MovementStateChanged || ev.Previous == nil || ev.Previous.State != "pending" || ev.Movement.State != "completed" {
+		t.Fatalf("unexpected second event: %+v", ev)
+	}
+	cancel()
+	for range events {
+	}
+
+	// A restarted watcher resumes from the cursor without re-emitting m1
+	fake.set("m2", "pending")
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	events = client.WatchMovements(ctx, nil, opts)
+	if ev := nextEvent(t, events); ev.Movement.IDString() != "m2" {
+		t.Fatalf("expected m2 after restart, got %+v", ev)
+	}
+
+	// The previous version of m1 came back with the cursor
+	fake.set("m1", "cancelled")
+	if ev := nextEvent(t, events); ev.Previous == nil || ev.Previous.IDString() != "m1" || ev.Previous.State != "completed" {
+		t.Fatalf("expected m1 change from completed, got %+v", ev)
+	}
+}
+
+func TestWatchMovementsDoesNotReemitEvicted(t *testing.T) {
+	now := time.Now().UTC()
+	fake := &fakeBusiness{}
+	for i, state := range []string{"completed", "pending", "pending"} {
+		fake.items = append(fake.items, map[string]interface{}{
+			"id": fmt.Sprintf("m%d", i+1), "state": state,
+			"createdAt": now.Add(time.Duration(i-3) * time.Minute).Format(time.RFC3339),
+		})
+	}
+	client := newFakeClient(t, fake)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := client.WatchMovements(ctx, nil, &gotropipay.WatchOptions{
+		MinInterval: time.Millisecond,
+		MaxInterval: 5 * time.Millisecond,
+		Since:       now.Add(-time.Hour),
+		MaxTracked:  2,
+	})
+	for i := 1; i <= 3; i++ {
+		if ev := nextEvent(t, events); ev.Type != gotropipay.MovementCreated || ev.Movement.IDString() != fmt.Sprintf("m%d", i) {
+			t.Fatalf("event %d: %+v", i, ev)
+		}
+	}
+
+	// m1 was forgotten to stay within MaxTracked but must not come back as new
+	time.Sleep(20 * time.Millisecond)
+	fake.set("m2", "completed")
+	if ev := nextEvent(t, events); ev.Type != gotropipay.MovementStateChanged || ev.Movement.IDString() != "m2" || ev.Previous == nil || ev.Previous.State != "pending" {
+		t.Fatalf("got %+v, want m2 state change", ev)
+	}
+}
+
+type memoryCursor struct {
+	mu    sync.Mutex
+	saved *gotropipay.WatchCursor
+	saves chan struct{}
+}
+
+func (m *memoryCursor) LoadCursor(context.Context) (*gotropipay.WatchCursor, error) { return nil, nil }
+
+func (m *memoryCursor) SaveCursor(ctx context.Context, c *gotropipay.WatchCursor) error {
+	m.mu.Lock()
+	m.saved = c
+	m.mu.Unlock()
+	m.saves <- struct{}{}
+	return nil
+}
+
+func TestWatchMovementsPersistsWebhookCursor(t *testing.T) {
+	client := newFakeClient(t, &fakeBusiness{})
+	listener := client.NewWebhookListener()
+	cursor := &memoryCursor{saves: make(chan struct{}, 10)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := client.WatchMovements(ctx, nil, &gotropipay.WatchOptions{
+		MinInterval: time.Hour, // only the first poll runs
+		MaxInterval: time.Hour,
+		Since:       time.Now().Add(-time.Hour),
+		Cursor:      cursor,
+		Webhook:     listener,
+	})
+	<-cursor.saves
+
+	created := time.Now().UTC().Truncate(time.Second)
+	listener.Dispatch(gotropipay.WebhookEvent{Status: "OK", Data: gotropipay.WebhookData{ID: "w1", CreatedAt: created.Format(time.RFC3339)}})
+	if ev := nextEvent(t, events); ev.Movement.IDString() != "w1" {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+	select {
+	case <-cursor.saves:
+	case <-time.After(2 * time.Second):
+		t.Fatal("cursor not saved after webhook")
+	}
+	cursor.mu.Lock()
+	defer cursor.mu.Unlock()
+	if !cursor.saved.Since.Equal(created) || len(cursor.saved.Seen) != 1 {
+		t.Errorf("saved cursor = %+v", cursor.saved)
+	}
+}
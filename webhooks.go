@@ -0,0 +1,139 @@
+package gotropipay
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WebhookEvent represents a notification POSTed by Tropipay to a paylink's urlNotification
+type WebhookEvent struct {
+	Status string      `json:"status"` // "OK" for successful payments, "KO" otherwise
+	Data   WebhookData `json:"data"`
+}
+
+// WebhookData holds the charge details carried by a webhook notification
+type WebhookData struct {
+	ID                     interface{} `json:"id"`
+	Reference              string      `json:"reference"`
+	BankOrderCode          string      `json:"bankOrderCode"`
+	Amount                 int64       `json:"amount"`
+	Currency               string      `json:"currency"`
+	OriginalCurrencyAmount int64       `json:"originalCurrencyAmount"`
+	DestinationAmount      int64       `json:"destinationAmount"`
+	DestinationCurrency    string      `json:"destinationCurrency"`
+	Signature              string      `json:"signature"`
+	CreatedAt              string      `json:"createdAt"`
+	UpdatedAt              string      `json:"updatedAt"`
+}
+
+// Succeeded reports whether the notification signals a completed payment
+func (e WebhookEvent) Succeeded() bool {
+	return strings.EqualFold(e.Status, "OK")
+}
+
+// Movement converts the notification into the standard Movement struct
+func (e WebhookEvent) Movement() Movement {
+	state := MovementStateFailed
+	if e.Succeeded() {
+		state = MovementStateCompleted
+	}
+	return Movement{
+		ID:          e.Data.ID,
+		Amount:      e.Data.Amount,
+		Currency:    e.Data.Currency,
+		State:       string(state),
+		Reference:   e.Data.Reference,
+		CreatedAt:   e.Data.CreatedAt,
+		CompletedAt: e.Data.UpdatedAt,
+	}
+}
+
+// VerifySignature checks the notification signature against the client credentials.
+// Tropipay signs with sha256(bankOrderCode + clientId + clientSecret + originalCurrencyAmount).
+func (e WebhookEvent) VerifySignature(clientID, clientSecret string) bool {
+	sum := sha256.Sum256([]byte(e.Data.BankOrderCode + clientID + clientSecret + strconv.FormatInt(e.Data.OriginalCurrencyAmount, 10)))
+	expected := hex.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(e.Data.Signature))) == 1
+}
+
+// WebhookListener is an http.Handler that receives Tropipay notifications
+// and fans them out to subscribers
+type WebhookListener struct {
+	clientID     string
+	clientSecret string
+
+	// InsecureSkipVerify disables signature verification (only for local testing)
+	InsecureSkipVerify bool
+
+	mu   sync.Mutex
+	subs map[int]func(WebhookEvent)
+	next int
+}
+
+// NewWebhookListener creates a listener that verifies notifications with the client credentials
+func (c *Client) NewWebhookListener() *WebhookListener {
+	return &WebhookListener{
+		clientID:     c.clientID,
+		clientSecret: c.clientSecret,
+		subs:         make(map[int]func(WebhookEvent)),
+	}
+}
+
+// Subscribe registers fn to be called for every verified notification.
+// The returned function removes the subscription.
+func (l *WebhookListener) Subscribe(fn func(WebhookEvent)) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := l.next
+	l.next++
+	l.subs[id] = fn
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subs, id)
+	}
+}
+
+// Dispatch delivers an event to all subscribers, bypassing HTTP decoding and verification
+func (l *WebhookListener) Dispatch(event WebhookEvent) {
+	l.mu.Lock()
+	subs := make([]func(WebhookEvent), 0, len(l.subs))
+	for _, fn := range l.subs {
+		subs = append(subs, fn)
+	}
+	l.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(event)
+	}
+}
+
+// ServeHTTP decodes and verifies a notification, then dispatches it
+func (l *WebhookListener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var event WebhookEvent
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&event); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if !l.InsecureSkipVerify && !event.VerifySignature(l.clientID, l.clientSecret) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	l.Dispatch(event)
+	w.WriteHeader(http.StatusOK)
+}